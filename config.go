@@ -0,0 +1,166 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/klog/v2"
+
+	configv1alpha1 "k8s.io/sample-controller/pkg/apis/config/v1alpha1"
+	configvalidation "k8s.io/sample-controller/pkg/apis/config/validation"
+)
+
+var (
+	configScheme = runtime.NewScheme()
+	// configCodecs decode the configuration file strictly, so that a
+	// misspelled field is an error instead of being silently ignored.
+	configCodecs = serializer.NewCodecFactory(configScheme, serializer.EnableStrict)
+)
+
+func init() {
+	utilruntime.Must(configv1alpha1.AddToScheme(configScheme))
+}
+
+// loadConfig reads the controller configuration from the file at path, or
+// starts from an empty one if path is empty, and defaults the fields left
+// unset. The flags set on the command line take precedence over both. The
+// result is validated.
+func loadConfig(path string) (*configv1alpha1.BookstoreControllerConfiguration, error) {
+	cfg := &configv1alpha1.BookstoreControllerConfiguration{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := runtime.DecodeInto(configCodecs.UniversalDecoder(configv1alpha1.SchemeGroupVersion), data, cfg); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", path, err)
+		}
+	}
+	cfg.APIVersion, cfg.Kind = configv1alpha1.SchemeGroupVersion.WithKind("BookstoreControllerConfiguration").ToAPIVersionAndKind()
+
+	configScheme.Default(cfg)
+	applyFlagOverrides(cfg)
+	if errs := configvalidation.ValidateBookstoreControllerConfiguration(cfg); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration: %w", errs.ToAggregate())
+	}
+	return cfg, nil
+}
+
+// commandLineFlags returns the flags set on the command line with their
+// values. They are captured on first use, at startup, before -v can be
+// changed by applyVerbosity.
+var commandLineFlags = sync.OnceValue(func() map[string]string {
+	flags := map[string]string{}
+	flag.Visit(func(f *flag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+	return flags
+})
+
+// applyFlagOverrides copies the configuration flags set on the command line
+// into cfg.
+func applyFlagOverrides(cfg *configv1alpha1.BookstoreControllerConfiguration) {
+	for name, value := range commandLineFlags() {
+		switch name {
+		case "workers":
+			cfg.Workers = int32(workers)
+		case "resync-period":
+			cfg.ResyncPeriod.Duration = resyncPeriod
+		case "rate-limiter-base-delay":
+			cfg.RateLimiter.BaseDelay.Duration = rateLimiterBaseDelay
+		case "rate-limiter-max-delay":
+			cfg.RateLimiter.MaxDelay.Duration = rateLimiterMaxDelay
+		case "rate-limiter-qps":
+			cfg.RateLimiter.QPS = float32(rateLimiterQPS)
+		case "rate-limiter-burst":
+			cfg.RateLimiter.Burst = int32(rateLimiterBurst)
+		case "kube-api-qps":
+			cfg.ClientConnection.QPS = float32(kubeAPIQPS)
+		case "kube-api-burst":
+			cfg.ClientConnection.Burst = int32(kubeAPIBurst)
+		case "v":
+			if v, err := strconv.ParseInt(value, 10, 32); err == nil {
+				cfg.Verbosity = int32(v)
+			}
+		}
+	}
+}
+
+// applyVerbosity sets the klog verbosity to the one of cfg.
+func applyVerbosity(cfg *configv1alpha1.BookstoreControllerConfiguration) error {
+	return flag.Set("v", strconv.Itoa(int(cfg.Verbosity)))
+}
+
+// reloadConfig reloads the configuration file at path on every value
+// received from reload, until ctx is cancelled. The settings that are safe
+// to change while the controller runs are applied: the log verbosity, the
+// rate limiter and the number of workers. Changes to other settings are
+// logged and only take effect on restart. An invalid configuration is
+// rejected as a whole and the current one is kept.
+func reloadConfig(ctx context.Context, reload <-chan struct{}, path string, current *configv1alpha1.BookstoreControllerConfiguration, controller *Controller) {
+	logger := klog.FromContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-reload:
+		}
+
+		logger.Info("Reloading configuration", "path", path)
+		cfg, err := loadConfig(path)
+		if err != nil {
+			logger.Error(err, "Error reloading configuration, keeping the current one")
+			continue
+		}
+
+		if cfg.Verbosity != current.Verbosity {
+			if err := applyVerbosity(cfg); err != nil {
+				logger.Error(err, "Error setting log verbosity")
+			}
+		}
+		if cfg.RateLimiter != current.RateLimiter {
+			controller.SetRateLimiter(cfg.RateLimiter)
+		}
+		if cfg.Workers != current.Workers {
+			controller.SetWorkers(int(cfg.Workers))
+		}
+		if cfg.ResyncPeriod != current.ResyncPeriod || cfg.ClientConnection != current.ClientConnection {
+			logger.Info("Changes to resyncPeriod and clientConnection take effect on restart")
+			cfg.ResyncPeriod, cfg.ClientConnection = current.ResyncPeriod, current.ClientConnection
+		}
+
+		current = cfg
+		if encoded, err := encodeConfig(current); err == nil {
+			logger.Info("Effective configuration", "config", encoded)
+		}
+	}
+}
+
+// encodeConfig returns cfg as JSON, e.g. for logging.
+func encodeConfig(cfg *configv1alpha1.BookstoreControllerConfiguration) (string, error) {
+	data, err := runtime.Encode(configCodecs.LegacyCodec(configv1alpha1.SchemeGroupVersion), cfg)
+	return string(data), err
+}
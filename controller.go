@@ -19,30 +19,25 @@ package main
 import (
 	"context"
 	"fmt"
-	"k8s.io/apimachinery/pkg/util/intstr"
-	v12 "k8s.io/client-go/informers/core/v1"
-	v1 "k8s.io/client-go/listers/core/v1"
-	"time"
-
-	"golang.org/x/time/rate"
+	"sync"
+	"sync/atomic"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	appsinformers "k8s.io/client-go/informers/apps/v1"
+	v12 "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
-	appslisters "k8s.io/client-go/listers/apps/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
-	samplev1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+	configv1alpha1 "k8s.io/sample-controller/pkg/apis/config/v1alpha1"
+	"k8s.io/sample-controller/pkg/controller/bookstore"
 	clientset "k8s.io/sample-controller/pkg/generated/clientset/versioned"
 	samplescheme "k8s.io/sample-controller/pkg/generated/clientset/versioned/scheme"
 	informers "k8s.io/sample-controller/pkg/generated/informers/externalversions/calico/v1alpha1"
@@ -51,34 +46,22 @@ import (
 
 const controllerAgentName = "sample-controller"
 
-const (
-	// SuccessSynced is used as part of the Event 'reason' when a Bookstore is synced
-	SuccessSynced = "Synced"
-	// ErrResourceExists is used as part of the Event 'reason' when a Bookstore fails
-	// to sync due to a Deployment of the same name already existing.
-	ErrResourceExists = "ErrResourceExists"
-
-	// MessageResourceExists is the message used for Events when a resource
-	// fails to sync due to a Deployment already existing
-	MessageResourceExists = "Resource %q already exists and is not managed by Bookstore"
-	// MessageResourceSynced is the message used for an Event fired when a Bookstore
-	// is synced successfully
-	MessageResourceSynced = "Bookstore synced successfully"
-)
-
-// Controller is the controller implementation for Bookstore resources
+// Controller is the controller implementation for Bookstore resources. It
+// queues the Bookstores whose objects change, and hands them to its
+// bookstore.Reconciler.
 type Controller struct {
-	// kubeclientset is a standard kubernetes clientset
-	kubeclientset kubernetes.Interface
-	// sampleclientset is a clientset for our own API group
-	sampleclientset clientset.Interface
-
-	serviceLister     v1.ServiceLister
 	serviceSynced     cache.InformerSynced
-	deploymentsLister appslisters.DeploymentLister
+	secretsSynced     cache.InformerSynced
+	configMapsSynced  cache.InformerSynced
 	deploymentsSynced cache.InformerSynced
 	bookstoresLister  listers.BookstoreLister
 	bookstoresSynced  cache.InformerSynced
+	// bookstoresIndexer finds the Bookstores that reference a Secret or
+	// ConfigMap, using the indexes from bookstoreIndexers.
+	bookstoresIndexer cache.Indexer
+
+	// reconciler reconciles the Bookstores taken off the workqueue.
+	reconciler bookstore.Reconciler
 
 	// workqueue is a rate limited work queue. This is used to queue work to be
 	// processed instead of performing it as soon as a change happens. This
@@ -86,9 +69,25 @@ type Controller struct {
 	// time, and makes it easy to ensure we are never processing the same item
 	// simultaneously in two different workers.
 	workqueue workqueue.TypedRateLimitingInterface[string]
-	// recorder is an event recorder for recording Event resources to the
-	// Kubernetes API.
-	recorder record.EventRecorder
+	// rateLimiter delays the Bookstores requeued by the workqueue. It is
+	// reconfigured by SetRateLimiter.
+	rateLimiter *rateLimiter
+
+	// workersMu guards the pool of workers started by Run and resized by
+	// SetWorkers. workersCtx is the context of Run, and workerCancels stops
+	// each worker.
+	workersMu     sync.Mutex
+	workers       int
+	workersCtx    context.Context
+	workerCancels []context.CancelFunc
+	workersWG     sync.WaitGroup
+
+	// workersRunning, lastProgress, the time in Unix nanoseconds a worker
+	// last picked up or completed an item, and queuedSince, the time the
+	// workqueue last became non-empty, are read by Healthz.
+	workersRunning atomic.Bool
+	lastProgress   atomic.Int64
+	queuedSince    atomic.Int64
 }
 
 // NewController returns a new sample controller
@@ -98,9 +97,16 @@ func NewController(
 	sampleclientset clientset.Interface,
 	deploymentInformer appsinformers.DeploymentInformer,
 	serviceInformer v12.ServiceInformer,
-	bookstoreInformer informers.BookstoreInformer) *Controller {
+	secretInformer v12.SecretInformer,
+	configMapInformer v12.ConfigMapInformer,
+	bookstoreInformer informers.BookstoreInformer,
+	rateLimiterConfig configv1alpha1.RateLimiterConfiguration) (*Controller, error) {
 	logger := klog.FromContext(ctx)
 
+	if err := bookstoreInformer.Informer().AddIndexers(bookstoreIndexers()); err != nil {
+		return nil, err
+	}
+
 	// Create event broadcaster
 	// Add sample-controller types to the default Kubernetes Scheme so Events can be
 	// logged for sample-controller types.
@@ -111,23 +117,29 @@ func NewController(
 	eventBroadcaster.StartStructuredLogging(0)
 	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclientset.CoreV1().Events("")})
 	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: controllerAgentName})
-	ratelimiter := workqueue.NewTypedMaxOfRateLimiter(
-		workqueue.NewTypedItemExponentialFailureRateLimiter[string](5*time.Millisecond, 1000*time.Second),
-		&workqueue.TypedBucketRateLimiter[string]{Limiter: rate.NewLimiter(rate.Limit(50), 300)},
-	)
+	ratelimiter := newRateLimiter(rateLimiterConfig)
+
+	// Older API servers reject the sleep action of preStop hooks, in which
+	// case the Deployments run the sleep binary of the image instead.
+	sleepAction, err := bookstore.SupportsSleepAction(kubeclientset.Discovery())
+	if err != nil {
+		logger.Error(err, "Error getting the server version, falling back to exec preStop hooks")
+	}
+	logger.V(4).Info("Checked the server version", "sleepAction", sleepAction)
 
 	controller := &Controller{
-		kubeclientset:     kubeclientset,
-		sampleclientset:   sampleclientset,
-		serviceLister:     serviceInformer.Lister(),
 		serviceSynced:     serviceInformer.Informer().HasSynced,
-		deploymentsLister: deploymentInformer.Lister(),
+		secretsSynced:     secretInformer.Informer().HasSynced,
+		configMapsSynced:  configMapInformer.Informer().HasSynced,
 		deploymentsSynced: deploymentInformer.Informer().HasSynced,
 		bookstoresLister:  bookstoreInformer.Lister(),
 		bookstoresSynced:  bookstoreInformer.Informer().HasSynced,
-		workqueue:         workqueue.NewTypedRateLimitingQueue(ratelimiter),
-		recorder:          recorder,
+		bookstoresIndexer: bookstoreInformer.Informer().GetIndexer(),
+		reconciler: bookstore.NewReconciler(kubeclientset, sampleclientset,
+			deploymentInformer, serviceInformer, secretInformer, configMapInformer, bookstoreInformer, recorder, sleepAction),
+		rateLimiter: ratelimiter,
 	}
+	controller.workqueue = newWorkqueue(ratelimiter, &controller.queuedSince)
 
 	logger.Info("Setting up event handlers")
 	// Set up an event handler for when Bookstore resources change
@@ -171,7 +183,14 @@ func NewController(
 		DeleteFunc: controller.handleObject,
 	})
 
-	return controller
+	// Secrets and ConfigMaps are not owned by Bookstores, so the Bookstores
+	// to sync when one of them changes are looked up in the index instead.
+	// The sync then stamps a new config hash on the pod template, which
+	// rolls the pods of exactly those Bookstores.
+	secretInformer.Informer().AddEventHandler(controller.referencedObjectHandler(secretIndex))
+	configMapInformer.Informer().AddEventHandler(controller.referencedObjectHandler(configMapIndex))
+
+	return controller, nil
 }
 
 // Run will set up the event handlers for types we are interested in, as well
@@ -189,33 +208,42 @@ func (c *Controller) Run(ctx context.Context, workers int) error {
 	// Wait for the caches to be synced before starting workers
 	logger.Info("Waiting for informer caches to sync")
 
-	if ok := cache.WaitForCacheSync(ctx.Done(), c.deploymentsSynced, c.bookstoresSynced); !ok {
+	if ok := cache.WaitForCacheSync(ctx.Done(), c.informersSynced()...); !ok {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 
-	logger.Info("Starting workers", "count", workers)
-	// Launch two workers to process Bookstore resources
-	for i := 0; i < workers; i++ {
-		go wait.UntilWithContext(ctx, c.runWorker, time.Second)
-	}
+	// Launch the workers to process Bookstore resources. SetWorkers may
+	// change their number later on.
+	c.recordProgress()
+	c.workersRunning.Store(true)
+	defer c.workersRunning.Store(false)
+	c.startWorkers(ctx, workers)
 
 	logger.Info("Started workers")
 	<-ctx.Done()
 	logger.Info("Shutting down workers")
 
+	// Let the workers finish the items they are processing, so that the
+	// controller never stops halfway through a sync, e.g. when another
+	// replica takes over as leader.
+	c.workqueue.ShutDown()
+	c.workersWG.Wait()
+	logger.Info("Shut down workers")
+
 	return nil
 }
 
 // runWorker is a long-running function that will continually call the
 // processNextWorkItem function in order to read and process a message on the
-// workqueue.
+// workqueue. It returns once ctx is cancelled, after the item it is
+// processing.
 func (c *Controller) runWorker(ctx context.Context) {
-	for c.processNextWorkItem(ctx) {
+	for ctx.Err() == nil && c.processNextWorkItem(ctx) {
 	}
 }
 
 // processNextWorkItem will read a single work item off the workqueue and
-// attempt to process it, by calling the syncHandler.
+// attempt to process it, by calling the reconciler.
 func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	obj, shutdown := c.workqueue.Get()
 	logger := klog.FromContext(ctx)
@@ -223,6 +251,8 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	if shutdown {
 		return false
 	}
+	c.recordProgress()
+	defer c.recordProgress()
 
 	// We wrap this block in a func so we can defer c.workqueue.Done.
 	err := func(key string) error {
@@ -233,18 +263,43 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 		// put back on the workqueue and attempted again after a back-off
 		// period.
 		defer c.workqueue.Done(key)
-		// Run the syncHandler, passing it the namespace/name string of the
+		// Run the reconciler, passing it the namespace/name string of the
 		// Bookstore resource to be synced.
-		if err := c.syncHandler(ctx, key); err != nil {
-			// Put the item back on the workqueue to handle any transient errors.
+		result, err := c.reconciler.Reconcile(ctx, key)
+		if err == nil {
+			// Finally, if no error occurs we Forget this item so it does not
+			// get queued again until another change happens, or until the
+			// follow-up the reconciler asked for.
+			c.workqueue.Forget(obj)
+			if result.RequeueAfter > 0 {
+				c.workqueue.AddAfter(key, result.RequeueAfter)
+			}
+			logger.Info("Successfully synced", "resourceName", key)
+			return nil
+		}
+
+		// Put the item back on the workqueue in the way the class of the
+		// error calls for.
+		switch bookstore.ClassifyError(err) {
+		case bookstore.ErrorTerminal:
+			// The Bookstore has been marked Failed, and retrying cannot help
+			// until its spec changes, which queues it again.
+			c.workqueue.Forget(obj)
+			return fmt.Errorf("error syncing '%s': %s, not requeuing until the spec changes", key, err.Error())
+		case bookstore.ErrorConflict:
+			// The informer cache is catching up with the write that
+			// conflicted, so the next attempt is likely to succeed.
+			c.workqueue.Add(key)
+		case bookstore.ErrorRateLimited:
+			if delay, ok := bookstore.RetryAfter(err); ok {
+				c.workqueue.AddAfter(key, delay)
+			} else {
+				c.workqueue.AddRateLimited(key)
+			}
+		default:
 			c.workqueue.AddRateLimited(key)
-			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
 		}
-		// Finally, if no error occurs we Forget this item so it does not
-		// get queued again until another change happens.
-		c.workqueue.Forget(obj)
-		logger.Info("Successfully synced", "resourceName", key)
-		return nil
+		return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
 	}(obj)
 
 	if err != nil {
@@ -255,128 +310,6 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	return true
 }
 
-// syncHandler compares the actual state with the desired, and attempts to
-// converge the two. It then updates the Status block of the Bookstore resource
-// with the current status of the resource.
-func (c *Controller) syncHandler(ctx context.Context, key string) error {
-	// Convert the namespace/name string into a distinct namespace and name
-	logger := klog.LoggerWithValues(klog.FromContext(ctx), "resourceName", key)
-
-	namespace, name, err := cache.SplitMetaNamespaceKey(key)
-	if err != nil {
-		logger.Error(err, "invalid resource key")
-		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
-		return nil
-	}
-
-	// Get the Booksore resource with this namespace/name
-	bookstore, err := c.bookstoresLister.Bookstores(namespace).Get(name)
-	if err != nil {
-		// The Bookstore resource may no longer exist, in which case we stop
-		// processing.
-		if errors.IsNotFound(err) {
-			utilruntime.HandleError(fmt.Errorf("bookstore '%s' in work queue no longer exists", key))
-			return nil
-		}
-		logger.Error(err, "bookstore lister unknown error")
-		return err
-	}
-
-	if bookstore.Spec.DeploymentName == "" {
-		// We choose to absorb the error here as the worker would requeue the
-		// resource otherwise. Instead, the next time the resource is updated
-		// the resource will be queued again.
-		utilruntime.HandleError(fmt.Errorf("%s: deployment name must be specified", key))
-		return nil
-	}
-
-	// Get the deployment with the name specified in Bookstore.spec
-	deployment, err := c.deploymentsLister.Deployments(bookstore.Namespace).Get(bookstore.Spec.DeploymentName)
-	// If the resource doesn't exist, we'll create it
-	if errors.IsNotFound(err) {
-		deployment, err = c.kubeclientset.AppsV1().Deployments(bookstore.Namespace).Create(context.TODO(), newDeployment(bookstore), metav1.CreateOptions{})
-	}
-
-	// If an error occurs during Get/Create, we'll requeue the item so we can
-	// attempt processing again later. This could have been caused by a
-	// temporary network failure, or any other transient reason.
-	if err != nil {
-		logger.Error(err, "error creating deployments")
-		return err
-	}
-
-	// If the Deployment is not controlled by this Bookstore resource, we should log
-	// a warning to the event recorder and return error msg.
-	if !metav1.IsControlledBy(deployment, bookstore) {
-		msg := fmt.Sprintf(MessageResourceExists, deployment.Name)
-		c.recorder.Event(bookstore, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return fmt.Errorf("%s", msg)
-	}
-
-	// If this number of the replicas on the Bookstore resource is specified, and the
-	// number does not equal the current desired replicas on the Deployment, we
-	// should update the Deployment resource.
-	if bookstore.Spec.Replicas != nil && *bookstore.Spec.Replicas != *deployment.Spec.Replicas {
-		logger.V(4).Info("Update deployment resource", "currentReplicas", *bookstore.Spec.Replicas, "desiredReplicas", *deployment.Spec.Replicas)
-		deployment, err = c.kubeclientset.AppsV1().Deployments(bookstore.Namespace).Update(context.TODO(), newDeployment(bookstore), metav1.UpdateOptions{})
-	}
-
-	// If an error occurs during Update, we'll requeue the item so we can
-	// attempt processing again later. This could have been caused by a
-	// temporary network failure, or any other transient reason.
-	if err != nil {
-		logger.Error(err, "error updating deployment")
-		return err
-	}
-
-	if bookstore.Spec.ServiceName == "" {
-		utilruntime.HandleError(fmt.Errorf("%s: service name must be specified", key))
-		return nil
-	}
-
-	service, err := c.serviceLister.Services(bookstore.Namespace).Get(bookstore.Spec.ServiceName)
-	if errors.IsNotFound(err) {
-		service, err = c.kubeclientset.CoreV1().Services(bookstore.Namespace).Create(context.TODO(), newService(bookstore), metav1.CreateOptions{})
-	}
-
-	if err != nil {
-		logger.Error(err, "error creating service")
-		return err
-	}
-
-	if !metav1.IsControlledBy(service, bookstore) {
-		msg := fmt.Sprintf(MessageResourceExists, service.Name)
-		c.recorder.Event(bookstore, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return fmt.Errorf("%s", msg)
-	}
-
-	// Finally, we update the status block of the Bookstore resource to reflect the
-	// current state of the world
-	err = c.updateBookstoreStatus(bookstore, deployment)
-	if err != nil {
-		logger.Error(err, "error updating bookstore status")
-		return err
-	}
-
-	//fmt.Println("\n-------------------------------------------------------\nNO ERROR\n-------------------------------------------------------\n")
-	c.recorder.Event(bookstore, corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
-	return nil
-}
-
-func (c *Controller) updateBookstoreStatus(bookstore *samplev1alpha1.Bookstore, deployment *appsv1.Deployment) error {
-	// NEVER modify objects from the store. It's a read-only, local cache.
-	// You can use DeepCopy() to make a deep copy of original object and modify this copy
-	// Or create a copy manually for better performance
-	bookstoreCopy := bookstore.DeepCopy()
-	bookstoreCopy.Status.AvailableReplicas = deployment.Status.AvailableReplicas
-	// If the CustomResourceSubresources feature gate is not enabled,
-	// we must use Update instead of UpdateStatus to update the Status block of the Bookstore resource.
-	// UpdateStatus will not allow changes to the Spec of the resource,
-	// which is ideal for ensuring nothing other than resource status has been updated.
-	_, err := c.sampleclientset.CalicoV1alpha1().Bookstores(bookstore.Namespace).UpdateStatus(context.TODO(), bookstoreCopy, metav1.UpdateOptions{})
-	return err
-}
-
 // enqueueBookstore takes a Bookstore resource and converts it into a namespace/name
 // string which is then put onto the work queue. This method should *not* be
 // passed resources of any type other than Bookstore.
@@ -394,7 +327,9 @@ func (c *Controller) enqueueBookstore(obj interface{}) {
 // to find the Bookstore resource that 'owns' it. It does this by looking at the
 // objects metadata.ownerReferences field for an appropriate OwnerReference.
 // It then enqueues that Bookstore resource to be processed. If the object does not
-// have an appropriate OwnerReference, it will simply be skipped.
+// have an appropriate OwnerReference, the Bookstores that name it in their spec
+// are enqueued instead, so that a Bookstore that failed because the object
+// belongs to someone else is synced again once it is deleted.
 func (c *Controller) handleObject(obj interface{}) {
 	var object metav1.Object
 	var ok bool
@@ -413,13 +348,7 @@ func (c *Controller) handleObject(obj interface{}) {
 		logger.V(4).Info("Recovered deleted object", "resourceName", object.GetName())
 	}
 	logger.V(4).Info("Processing object", "object", klog.KObj(object))
-	if ownerRef := metav1.GetControllerOf(object); ownerRef != nil {
-		// If this object is not owned by a Bookstore, we should not do anything more
-		// with it.
-		if ownerRef.Kind != "Bookstore" {
-			return
-		}
-
+	if ownerRef := metav1.GetControllerOf(object); ownerRef != nil && ownerRef.Kind == "Bookstore" {
 		bookstore, err := c.bookstoresLister.Bookstores(object.GetNamespace()).Get(ownerRef.Name)
 		if err != nil {
 			logger.V(4).Info("Ignore orphaned object", "object", klog.KObj(object), "bookstore", ownerRef.Name)
@@ -429,133 +358,48 @@ func (c *Controller) handleObject(obj interface{}) {
 		c.enqueueBookstore(bookstore)
 		return
 	}
-}
-
-// newDeployment creates a new Deployment for a Bookstore resource. It also sets
-// the appropriate OwnerReferences on the resource so handleObject can discover
-// the Bookstore resource that 'owns' it.
-func newDeployment(bookstore *samplev1alpha1.Bookstore) *appsv1.Deployment {
-	return &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      bookstore.Spec.DeploymentName,
-			Namespace: bookstore.Namespace,
-			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(bookstore, samplev1alpha1.SchemeGroupVersion.WithKind("Bookstore")),
-			},
-		},
 
-		Spec: appsv1.DeploymentSpec{
-			Replicas: bookstore.Spec.Replicas,
-
-			Selector: &metav1.LabelSelector{
-				MatchLabels: bookstore.GetSelectorLabels(),
-			},
-
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: bookstore.GetSelectorLabels(),
-				},
-
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{
-							Name:            bookstore.Spec.DeploymentName,
-							Image:           bookstore.Spec.DeploymentImageName + ":" + bookstore.Spec.DeploymentImageTag,
-							ImagePullPolicy: corev1.PullPolicy(bookstore.Spec.ImagePullPolicy),
-
-							Ports: []corev1.ContainerPort{
-								{
-									ContainerPort: bookstore.Spec.ContainerPort,
-								},
-							},
-
-							Env: []corev1.EnvVar{
-								{
-									Name: "AdminUsername",
-									//Value: "admin22",
-									ValueFrom: &corev1.EnvVarSource{
-										SecretKeyRef: &corev1.SecretKeySelector{
-											LocalObjectReference: corev1.LocalObjectReference{
-												Name: "env-secrets",
-											},
-											Key: bookstore.Spec.EnvAdminUsername,
-											Optional: func() *bool {
-												var flag = false
-												return &flag
-											}(),
-										},
-									},
-								},
-
-								{
-									Name: "AdminPassword",
-									//Value: "admin72",
-									ValueFrom: &corev1.EnvVarSource{
-										SecretKeyRef: &corev1.SecretKeySelector{
-											LocalObjectReference: corev1.LocalObjectReference{
-												Name: "env-secrets",
-											},
-											Key: bookstore.Spec.EnvAdminPassword,
-											Optional: func() *bool {
-												var flag = false
-												return &flag
-											}(),
-										},
-									},
-								},
-
-								{
-									Name: "JWTSECRET",
-									//Value: "orangeCat",
-									ValueFrom: &corev1.EnvVarSource{
-										SecretKeyRef: &corev1.SecretKeySelector{
-											LocalObjectReference: corev1.LocalObjectReference{
-												Name: "env-secrets",
-											},
-											Key: bookstore.Spec.EnvJWTSECRET,
-											Optional: func() *bool {
-												var flag = false
-												return &flag
-											}(),
-										},
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-		},
+	indexName := deploymentIndex
+	if _, ok := object.(*corev1.Service); ok {
+		indexName = serviceIndex
+	}
+	bookstores, err := c.bookstoresIndexer.ByIndex(indexName, object.GetNamespace()+"/"+object.GetName())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, bookstore := range bookstores {
+		c.enqueueBookstore(bookstore)
 	}
 }
 
-func newService(bookstore *samplev1alpha1.Bookstore) *corev1.Service {
-	fmt.Println("CREATING SERVICE")
-	return &corev1.Service{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Service",
-			APIVersion: "core/v1",
-		},
-
-		ObjectMeta: metav1.ObjectMeta{
-			Name:   bookstore.Spec.ServiceName,
-			Labels: bookstore.GetSelectorLabels(),
-			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(bookstore, samplev1alpha1.SchemeGroupVersion.WithKind("Bookstore")),
-			},
-		},
-
-		Spec: corev1.ServiceSpec{
-			Selector: bookstore.GetSelectorLabels(),
-			Type:     corev1.ServiceType(bookstore.Spec.ServiceType),
-			Ports: []corev1.ServicePort{
-				{
-					Protocol:   corev1.ProtocolTCP,
-					Port:       bookstore.Spec.ContainerPort,
-					TargetPort: intstr.FromInt32(bookstore.Spec.TargetPort),
-					NodePort:   bookstore.Spec.NodePort,
-				},
-			},
+// referencedObjectHandler returns an event handler that enqueues every
+// Bookstore found under the namespace/name key of the changed object in the
+// given index of the Bookstore informer.
+func (c *Controller) referencedObjectHandler(indexName string) cache.ResourceEventHandler {
+	enqueue := func(obj interface{}) {
+		key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+		if err != nil {
+			utilruntime.HandleError(err)
+			return
+		}
+		bookstores, err := c.bookstoresIndexer.ByIndex(indexName, key)
+		if err != nil {
+			utilruntime.HandleError(err)
+			return
+		}
+		for _, bookstore := range bookstores {
+			c.enqueueBookstore(bookstore)
+		}
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueue,
+		UpdateFunc: func(old, new interface{}) {
+			if old.(metav1.Object).GetResourceVersion() == new.(metav1.Object).GetResourceVersion() {
+				return
+			}
+			enqueue(new)
 		},
+		DeleteFunc: enqueue,
 	}
 }
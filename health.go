@@ -0,0 +1,120 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// informersSynced returns the HasSynced functions of every informer the
+// controller reads from.
+func (c *Controller) informersSynced() []cache.InformerSynced {
+	return []cache.InformerSynced{c.deploymentsSynced, c.serviceSynced, c.bookstoresSynced, c.secretsSynced, c.configMapsSynced}
+}
+
+// Readyz returns an error until the caches of all informers have synced.
+// Replicas that are not the leader become ready as well, so that they can
+// take over with warm caches.
+func (c *Controller) Readyz() error {
+	for _, synced := range c.informersSynced() {
+		if !synced() {
+			return fmt.Errorf("informer caches not synced")
+		}
+	}
+	return nil
+}
+
+// Healthz returns an error if Bookstores are queued but no worker has picked
+// up or completed one for longer than window, e.g. because the workers are
+// stuck on API calls that never return. The stall is measured from the later
+// of the last progress and the time the queue last became non-empty, so that
+// the first Bookstore queued after an idle period does not count the idle
+// time. It always succeeds while the workers are not running, before the
+// caches synced or on a replica that is not the leader.
+func (c *Controller) Healthz(window time.Duration) error {
+	if !c.workersRunning.Load() {
+		return nil
+	}
+	queued := c.workqueue.Len()
+	if queued == 0 {
+		return nil
+	}
+	since := max(c.lastProgress.Load(), c.queuedSince.Load())
+	if stalled := time.Since(time.Unix(0, since)); stalled > window {
+		return fmt.Errorf("workers made no progress for %s with %d Bookstores queued", stalled.Round(time.Second), queued)
+	}
+	return nil
+}
+
+// recordProgress marks that a worker picked up or completed an item.
+func (c *Controller) recordProgress() {
+	c.lastProgress.Store(time.Now().UnixNano())
+}
+
+// newWorkqueue returns the rate limited workqueue of the controller, which
+// stores the time it last became non-empty in queuedSince.
+func newWorkqueue(rateLimiter workqueue.TypedRateLimiter[string], queuedSince *atomic.Int64) workqueue.TypedRateLimitingInterface[string] {
+	const name = "Bookstores"
+	queue := workqueue.NewTypedWithConfig(workqueue.TypedQueueConfig[string]{
+		Name:  name,
+		Queue: &timedQueue{Queue: workqueue.DefaultQueue[string](), queuedSince: queuedSince},
+	})
+	return workqueue.NewTypedRateLimitingQueueWithConfig(rateLimiter, workqueue.TypedRateLimitingQueueConfig[string]{
+		DelayingQueue: workqueue.NewTypedDelayingQueueWithConfig(workqueue.TypedDelayingQueueConfig[string]{
+			Name:  name,
+			Queue: queue,
+		}),
+	})
+}
+
+// timedQueue is the FIFO behind the workqueue. Every item that becomes
+// ready, whether added directly, after a delay or again after it was
+// processed, is pushed to it, so it sees the queue become non-empty.
+type timedQueue struct {
+	workqueue.Queue[string]
+	// queuedSince is the time in Unix nanoseconds of the last push to the
+	// empty queue.
+	queuedSince *atomic.Int64
+}
+
+func (q *timedQueue) Push(item string) {
+	if q.Queue.Len() == 0 {
+		q.queuedSince.Store(time.Now().UnixNano())
+	}
+	q.Queue.Push(item)
+}
+
+// healthHandler serves the result of check: 200 and "ok" if it succeeds, 500
+// and the error otherwise.
+func healthHandler(check func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if err := check(); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "%s: %v\n", r.URL.Path, err)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	})
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"k8s.io/client-go/tools/cache"
+
+	samplev1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+)
+
+const (
+	// secretIndex and configMapIndex index Bookstores by the namespace/name
+	// key of the Secret and ConfigMap they reference.
+	secretIndex    = "secret"
+	configMapIndex = "configMap"
+	// deploymentIndex and serviceIndex index Bookstores by the namespace/name
+	// key of the Deployment and Service named in their spec.
+	deploymentIndex = "deployment"
+	serviceIndex    = "service"
+)
+
+// bookstoreIndexers returns the indexers used to find the Bookstores that
+// reference a Secret or ConfigMap, or name a Deployment or Service.
+func bookstoreIndexers() cache.Indexers {
+	return cache.Indexers{
+		deploymentIndex: func(obj interface{}) ([]string, error) {
+			bookstore, ok := defaultedBookstore(obj)
+			if !ok {
+				return nil, nil
+			}
+			return []string{bookstore.Namespace + "/" + bookstore.Spec.DeploymentName}, nil
+		},
+		serviceIndex: func(obj interface{}) ([]string, error) {
+			bookstore, ok := defaultedBookstore(obj)
+			if !ok {
+				return nil, nil
+			}
+			return []string{bookstore.Namespace + "/" + bookstore.Spec.ServiceName}, nil
+		},
+		secretIndex: func(obj interface{}) ([]string, error) {
+			bookstore, ok := obj.(*samplev1alpha1.Bookstore)
+			if !ok {
+				return nil, nil
+			}
+			return []string{bookstore.Namespace + "/" + bookstore.GetCredentialsSecretName()}, nil
+		},
+		configMapIndex: func(obj interface{}) ([]string, error) {
+			bookstore, ok := obj.(*samplev1alpha1.Bookstore)
+			if !ok || bookstore.Spec.ConfigMapRef == nil {
+				return nil, nil
+			}
+			return []string{bookstore.Namespace + "/" + bookstore.Spec.ConfigMapRef.Name}, nil
+		},
+	}
+}
+
+// defaultedBookstore returns a copy of obj, if it is a Bookstore, with the
+// child names defaulted as the reconciler defaults them.
+func defaultedBookstore(obj interface{}) (*samplev1alpha1.Bookstore, bool) {
+	bookstore, ok := obj.(*samplev1alpha1.Bookstore)
+	if !ok {
+		return nil, false
+	}
+	defaulted := *bookstore
+	samplev1alpha1.SetDefaults_Bookstore(&defaulted)
+	return &defaulted, true
+}
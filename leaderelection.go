@@ -0,0 +1,106 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+)
+
+// runLeaderElected calls run once this replica holds the leader election
+// Lease, and blocks until it returns. run is given a context that is cancelled
+// when ctx is or when the Lease is lost. Losing the Lease is returned as an
+// error after run has returned, since run cannot resume where it stopped.
+func runLeaderElected(ctx context.Context, kubeClient kubernetes.Interface, run func(context.Context) error) error {
+	logger := klog.FromContext(ctx)
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	// The UUID tells apart replicas that share a hostname, e.g. a restarted
+	// pod on the host network.
+	id := hostname + "_" + string(uuid.NewUUID())
+
+	lock, err := resourcelock.New(resourcelock.LeasesResourceLock,
+		leaderElectNamespace, leaderElectName,
+		kubeClient.CoreV1(), kubeClient.CoordinationV1(),
+		resourcelock.ResourceLockConfig{Identity: id})
+	if err != nil {
+		return err
+	}
+
+	leading := make(chan context.Context, 1)
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: leaderElectLeaseDuration,
+		RenewDeadline: leaderElectRenewDeadline,
+		RetryPeriod:   leaderElectRetryPeriod,
+		// Release the Lease on shutdown, so that another replica takes over
+		// at once instead of waiting for it to expire.
+		ReleaseOnCancel: true,
+		Name:            leaderElectName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				logger.Info("Started leading", "identity", id)
+				leading <- ctx
+			},
+			OnStoppedLeading: func() {
+				logger.Info("Stopped leading", "identity", id)
+			},
+			OnNewLeader: func(identity string) {
+				if identity != id {
+					logger.Info("New leader elected", "identity", identity)
+				}
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	electorDone := make(chan struct{})
+	go func() {
+		defer close(electorDone)
+		elector.Run(ctx)
+	}()
+
+	logger.Info("Waiting for leader election", "lease", klog.KRef(leaderElectNamespace, leaderElectName), "identity", id)
+	select {
+	case <-ctx.Done():
+		<-electorDone
+		return nil
+	case leaderCtx := <-leading:
+		// leaderCtx is cancelled when ctx is or when the Lease is lost.
+		err := run(leaderCtx)
+		<-electorDone
+		if err != nil {
+			return err
+		}
+		if ctx.Err() == nil {
+			return fmt.Errorf("leader election lost")
+		}
+		return nil
+	}
+}
@@ -17,9 +17,16 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
 	"time"
 
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/dynamic"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
@@ -30,11 +37,44 @@ import (
 
 	clientset "k8s.io/sample-controller/pkg/generated/clientset/versioned"
 	informers "k8s.io/sample-controller/pkg/generated/informers/externalversions"
+	"k8s.io/sample-controller/pkg/metrics"
+	"k8s.io/sample-controller/pkg/webhook"
 )
 
 var (
 	masterURL  string
 	kubeconfig string
+	configFile string
+
+	workers              int
+	resyncPeriod         time.Duration
+	rateLimiterBaseDelay time.Duration
+	rateLimiterMaxDelay  time.Duration
+	rateLimiterQPS       float64
+	rateLimiterBurst     int
+	kubeAPIQPS           float64
+	kubeAPIBurst         int
+
+	webhookPort                int
+	webhookCertDir             string
+	webhookCertSecretName      string
+	webhookCertSecretNamespace string
+	webhookURL                 string
+	webhookServiceName         string
+	webhookServiceNamespace    string
+	webhookServicePort         int
+	nodePortRange              string
+
+	metricsBindAddress     string
+	healthProbeBindAddress string
+	workerStallTimeout     time.Duration
+
+	leaderElect              bool
+	leaderElectLeaseDuration time.Duration
+	leaderElectRenewDeadline time.Duration
+	leaderElectRetryPeriod   time.Duration
+	leaderElectNamespace     string
+	leaderElectName          string
 )
 
 func main() {
@@ -45,11 +85,34 @@ func main() {
 	ctx := signals.SetupSignalHandler()
 	logger := klog.FromContext(ctx)
 
+	// client-go picks up the metrics providers when the first clientset and
+	// workqueue are created, so they are registered before anything else.
+	metrics.Register()
+	if metricsBindAddress != "0" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		startHTTPServer(ctx, "metrics", metricsBindAddress, mux)
+	}
+
+	controllerConfig, err := loadConfig(configFile)
+	if err != nil {
+		logger.Error(err, "Error loading configuration")
+		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+	}
+	if err := applyVerbosity(controllerConfig); err != nil {
+		logger.Error(err, "Error setting log verbosity")
+	}
+	if encoded, err := encodeConfig(controllerConfig); err == nil {
+		logger.Info("Effective configuration", "config", encoded)
+	}
+
 	cfg, err := clientcmd.BuildConfigFromFlags(masterURL, kubeconfig)
 	if err != nil {
 		logger.Error(err, "Error building kubeconfig")
 		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
 	}
+	cfg.QPS = controllerConfig.ClientConnection.QPS
+	cfg.Burst = int(controllerConfig.ClientConnection.Burst)
 
 	kubeClient, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
@@ -63,26 +126,187 @@ func main() {
 		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
 	}
 
-	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, time.Second*30)
-	exampleInformerFactory := informers.NewSharedInformerFactory(exampleClient, time.Second*30)
+	if webhookPort != 0 {
+		dynamicClient, err := dynamic.NewForConfig(cfg)
+		if err != nil {
+			logger.Error(err, "Error building dynamic client")
+			klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+		}
+		if err = startWebhookServer(ctx, kubeClient, dynamicClient); err != nil {
+			logger.Error(err, "Error starting webhook server")
+			klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+		}
+	}
+
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, controllerConfig.ResyncPeriod.Duration)
+	exampleInformerFactory := informers.NewSharedInformerFactory(exampleClient, controllerConfig.ResyncPeriod.Duration)
 
-	controller := NewController(ctx, kubeClient, exampleClient,
+	controller, err := NewController(ctx, kubeClient, exampleClient,
 		kubeInformerFactory.Apps().V1().Deployments(),
 		kubeInformerFactory.Core().V1().Services(),
-		exampleInformerFactory.Calico().V1alpha1().Bookstores())
+		kubeInformerFactory.Core().V1().Secrets(),
+		kubeInformerFactory.Core().V1().ConfigMaps(),
+		exampleInformerFactory.Calico().V1alpha1().Bookstores(),
+		controllerConfig.RateLimiter)
+	if err != nil {
+		logger.Error(err, "Error building controller")
+		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+	}
+
+	// SIGHUP reloads the configuration without restarting, so that the
+	// informer caches are kept.
+	go reloadConfig(ctx, signals.SetupReloadHandler(ctx), configFile, controllerConfig, controller)
+
+	if healthProbeBindAddress != "0" {
+		mux := http.NewServeMux()
+		mux.Handle("/healthz", healthHandler(func() error { return controller.Healthz(workerStallTimeout) }))
+		mux.Handle("/readyz", healthHandler(controller.Readyz))
+		startHTTPServer(ctx, "health probe", healthProbeBindAddress, mux)
+	}
 
 	// notice that there is no need to run Start methods in a separate goroutine. (i.e. go kubeInformerFactory.Start(ctx.done())
 	// Start method is non-blocking and runs all registered informers in a dedicated goroutine.
 	kubeInformerFactory.Start(ctx.Done())
 	exampleInformerFactory.Start(ctx.Done())
 
-	if err = controller.Run(ctx, 2); err != nil {
+	run := func(ctx context.Context) error {
+		return controller.Run(ctx, int(controllerConfig.Workers))
+	}
+	if leaderElect {
+		// The informers above keep running while this replica waits for the
+		// Lease, so a new leader starts with warm caches.
+		err = runLeaderElected(ctx, kubeClient, run)
+	} else {
+		err = run(ctx)
+	}
+	if err != nil {
 		logger.Error(err, "Error running controller")
 		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
 	}
 }
 
+// startHTTPServer serves handler on address in the background until ctx is
+// cancelled. name identifies the server in logs.
+func startHTTPServer(ctx context.Context, name, address string, handler http.Handler) {
+	logger := klog.FromContext(ctx)
+	server := &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error(err, "Error shutting down server", "server", name)
+		}
+	}()
+	go func() {
+		logger.Info("Starting server", "server", name, "address", address)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error(err, "Error running server", "server", name)
+			klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+		}
+	}()
+}
+
+// startWebhookServer makes sure a serving certificate exists, registers the
+// admission and conversion webhooks with the API server and starts serving
+// them in the background until ctx is cancelled.
+func startWebhookServer(ctx context.Context, kubeClient kubernetes.Interface, dynamicClient dynamic.Interface) error {
+	logger := klog.FromContext(ctx)
+
+	portRange, err := utilnet.ParsePortRange(nodePortRange)
+	if err != nil {
+		return err
+	}
+
+	clientConfig := webhook.ClientConfig{
+		URL:              webhookURL,
+		ServiceNamespace: webhookServiceNamespace,
+		ServiceName:      webhookServiceName,
+		ServicePort:      int32(webhookServicePort),
+	}
+	hosts, err := clientConfig.Hosts()
+	if err != nil {
+		return err
+	}
+	// Replicas share the certificate through a Secret, so that the CA bundle
+	// registered by any of them is trusted by the others.
+	if webhookCertSecretName != "" {
+		namespace := webhookCertSecretNamespace
+		if namespace == "" {
+			namespace = webhookServiceNamespace
+		}
+		clientConfig.CABundle, err = webhook.EnsureServingCertSecret(ctx, kubeClient, namespace, webhookCertSecretName, webhookCertDir, hosts)
+	} else {
+		clientConfig.CABundle, err = webhook.EnsureServingCert(webhookCertDir, hosts)
+	}
+	if err != nil {
+		return err
+	}
+
+	server := webhook.NewServer(webhookPort, webhookCertDir)
+	server.Register(webhook.ValidateBookstorePath, webhook.AdmissionHandler(webhook.ValidateBookstore(*portRange)))
+	server.Register(webhook.DefaultBookstorePath, webhook.AdmissionHandler(webhook.DefaultBookstore()))
+	server.Register(webhook.ConvertBookstorePath, webhook.ConvertBookstores())
+
+	if err := webhook.EnsureCRDConversion(ctx, dynamicClient, clientConfig); err != nil {
+		return err
+	}
+
+	if err := webhook.EnsureMutatingWebhookConfiguration(ctx, kubeClient, webhook.NewMutatingWebhookConfiguration(clientConfig)); err != nil {
+		return err
+	}
+	if err := webhook.EnsureValidatingWebhookConfiguration(ctx, kubeClient, webhook.NewValidatingWebhookConfiguration(clientConfig)); err != nil {
+		return err
+	}
+
+	go func() {
+		if err := server.Run(ctx); err != nil {
+			logger.Error(err, "Error running webhook server")
+			klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+		}
+	}()
+	return nil
+}
+
 func init() {
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
 	flag.StringVar(&masterURL, "master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
+
+	// The configuration flags override the configuration file. Their
+	// defaults are those of the configuration.
+	flag.StringVar(&configFile, "config", "", "Path to a BookstoreControllerConfiguration file. Flags set on the command line override its fields.")
+	flag.IntVar(&workers, "workers", 2, "The number of Bookstores synced concurrently.")
+	flag.DurationVar(&resyncPeriod, "resync-period", 30*time.Second, "How often the informers resync, which queues every Bookstore again.")
+	flag.DurationVar(&rateLimiterBaseDelay, "rate-limiter-base-delay", 5*time.Millisecond, "The backoff after the first failed sync of a Bookstore. It doubles on every further failure.")
+	flag.DurationVar(&rateLimiterMaxDelay, "rate-limiter-max-delay", 1000*time.Second, "The maximum backoff of a Bookstore.")
+	flag.Float64Var(&rateLimiterQPS, "rate-limiter-qps", 50, "The overall rate at which Bookstores are requeued.")
+	flag.IntVar(&rateLimiterBurst, "rate-limiter-burst", 300, "The number of Bookstores that may be requeued at once above -rate-limiter-qps.")
+	flag.Float64Var(&kubeAPIQPS, "kube-api-qps", 5, "The rate of requests to the API server allowed by each client.")
+	flag.IntVar(&kubeAPIBurst, "kube-api-burst", 10, "The number of requests a client may send at once above -kube-api-qps.")
+
+	flag.IntVar(&webhookPort, "webhook-port", 0, "The port to serve admission webhooks on. 0 disables the webhook server.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", filepath.Join(os.TempDir(), "sample-controller-webhook"), "The directory the webhook server reads its serving certificate from. Without -webhook-cert-secret-name, a self-signed certificate is generated there if missing or expiring.")
+	flag.StringVar(&webhookCertSecretName, "webhook-cert-secret-name", "sample-controller-webhook-cert", "The name of the Secret holding the webhook serving certificate shared by all replicas. It is created with a self-signed certificate if missing or expiring, and copied to -webhook-cert-dir. Empty keeps the certificate in -webhook-cert-dir only, which is only safe with a single replica.")
+	flag.StringVar(&webhookCertSecretNamespace, "webhook-cert-secret-namespace", "", "The namespace of the webhook serving certificate Secret. Defaults to -webhook-service-namespace.")
+	flag.StringVar(&webhookURL, "webhook-url", "", "The base URL the API server uses to reach the webhook server, e.g. https://127.0.0.1:9443 for a local API server. Overrides the webhook service flags.")
+	flag.StringVar(&webhookServiceName, "webhook-service-name", "sample-controller-webhook", "The name of the Service in front of the webhook server.")
+	flag.StringVar(&webhookServiceNamespace, "webhook-service-namespace", "default", "The namespace of the Service in front of the webhook server.")
+	flag.IntVar(&webhookServicePort, "webhook-service-port", 443, "The port of the Service in front of the webhook server.")
+	flag.StringVar(&nodePortRange, "service-node-port-range", "30000-32767", "The range the cluster allocates Service node ports from. Bookstores with a nodePort outside it are rejected.")
+
+	flag.StringVar(&metricsBindAddress, "metrics-bind-address", ":8080", "The address to serve Prometheus metrics on, at /metrics. 0 disables the metrics server.")
+	flag.StringVar(&healthProbeBindAddress, "health-probe-bind-address", ":8081", "The address to serve the /healthz and /readyz probes on. 0 disables the probe server.")
+	flag.DurationVar(&workerStallTimeout, "worker-stall-timeout", 5*time.Minute, "How long the workers may go without picking up or completing a queued Bookstore before /healthz fails.")
+
+	flag.BoolVar(&leaderElect, "leader-elect", false, "Elect a leader through a Lease before starting the workers, so that only one of several replicas reconciles Bookstores at a time.")
+	flag.DurationVar(&leaderElectLeaseDuration, "leader-elect-lease-duration", 15*time.Second, "The duration non-leaders wait after the last renewal of the Lease before taking it over.")
+	flag.DurationVar(&leaderElectRenewDeadline, "leader-elect-renew-deadline", 10*time.Second, "The duration the leader retries renewing the Lease before giving up leadership. Must be less than the lease duration.")
+	flag.DurationVar(&leaderElectRetryPeriod, "leader-elect-retry-period", 2*time.Second, "The duration between attempts to acquire or renew the Lease.")
+	flag.StringVar(&leaderElectNamespace, "leader-elect-resource-namespace", "default", "The namespace of the leader election Lease.")
+	flag.StringVar(&leaderElectName, "leader-elect-resource-name", "sample-controller", "The name of the leader election Lease.")
 }
@@ -0,0 +1,104 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DefaultSecretName is the credentials Secret read by Bookstores that do not
+// set a secretRef. It is the name every Bookstore used before secretRef was
+// introduced.
+const DefaultSecretName = "env-secrets"
+
+const (
+	// DefaultTerminationGracePeriodSeconds is the termination grace period
+	// of Bookstores that do not set one.
+	DefaultTerminationGracePeriodSeconds int64 = 30
+	// DefaultPreStopSleepSeconds is the preStop delay of Bookstores that do
+	// not set one. It leaves the Service endpoints time to drop a
+	// terminating pod before it stops serving.
+	DefaultPreStopSleepSeconds int64 = 5
+)
+
+// DefaultResources returns the compute resources of the bookstore container
+// of Bookstores that do not set any: enough to schedule the API next to
+// others, with a memory limit that keeps a leak from starving its node.
+func DefaultResources() *corev1.ResourceRequirements {
+	return &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("64Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+	}
+}
+
+func addDefaultingFuncs(scheme *runtime.Scheme) error {
+	return RegisterDefaults(scheme)
+}
+
+// SetDefaults_Bookstore fills in the spec fields of a Bookstore that can be
+// derived from its name or from other fields of the spec.
+func SetDefaults_Bookstore(obj *Bookstore) {
+	// Objects created with generateName have no name until after admission,
+	// so the child names can only be derived once it is known.
+	if obj.Name != "" {
+		if obj.Spec.DeploymentName == "" {
+			obj.Spec.DeploymentName = obj.Name + "-deployment"
+		}
+		if obj.Spec.ServiceName == "" {
+			obj.Spec.ServiceName = obj.Name + "-service"
+		}
+	}
+	if !obj.Spec.GenerateSecret && (obj.Spec.SecretRef == nil || obj.Spec.SecretRef.Name == "") {
+		obj.Spec.SecretRef = &corev1.LocalObjectReference{Name: DefaultSecretName}
+	}
+	if obj.Spec.TargetPort == 0 {
+		obj.Spec.TargetPort = obj.Spec.ContainerPort
+	}
+	if obj.Spec.ImagePullPolicy == "" {
+		obj.Spec.ImagePullPolicy = string(corev1.PullIfNotPresent)
+	}
+	if obj.Spec.ServiceType == "" {
+		obj.Spec.ServiceType = string(corev1.ServiceTypeClusterIP)
+	}
+	if obj.Spec.DeletionPolicy == "" {
+		obj.Spec.DeletionPolicy = DeletionPolicyDelete
+	}
+}
+
+// SetDefaults_WorkloadSpec fills in the resources and termination settings
+// of the bookstore container. Probes are left alone: without paths they
+// check that containerPort accepts connections.
+func SetDefaults_WorkloadSpec(obj *WorkloadSpec) {
+	if obj.Resources == nil {
+		obj.Resources = DefaultResources()
+	}
+	if obj.TerminationGracePeriodSeconds == nil {
+		period := DefaultTerminationGracePeriodSeconds
+		obj.TerminationGracePeriodSeconds = &period
+	}
+	if obj.PreStopSleepSeconds == nil {
+		sleep := DefaultPreStopSleepSeconds
+		obj.PreStopSleepSeconds = &sleep
+	}
+}
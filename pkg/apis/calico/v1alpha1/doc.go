@@ -15,7 +15,9 @@ limitations under the License.
 */
 
 // +k8s:deepcopy-gen=package
-// +groupName=calico
+// +k8s:defaulter-gen=TypeMeta
+// +k8s:openapi-gen=true
+// +groupName=calico.com
 
 // Package v1alpha1 is the v1alpha1 version of the API.
 package v1alpha1 // import "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
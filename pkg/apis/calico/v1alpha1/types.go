@@ -17,10 +17,14 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // +genclient
+// +genclient:method=GetScale,verb=get,subresource=scale,result=k8s.io/api/autoscaling/v1.Scale
+// +genclient:method=UpdateScale,verb=update,subresource=scale,input=k8s.io/api/autoscaling/v1.Scale,result=k8s.io/api/autoscaling/v1.Scale
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // Bookstore is a specification for a Bookstore resource
@@ -39,21 +43,172 @@ type BookstoreSpec struct {
 	EnvJWTSECRET        string `json:"envJWTSECRET"`
 	DeploymentImageName string `json:"deploymentImageName"`
 	DeploymentImageTag  string `json:"deploymentImageTag"`
-	ImagePullPolicy     string `json:"imagePullPolicy"`
-	DeploymentName      string `json:"deploymentName"`
-	Replicas            *int32 `json:"replicas"`
-	ServiceName         string `json:"serviceName"`
-	ServiceType         string `json:"serviceType"`
-	ContainerPort       int32  `json:"containerPort"`
-	NodePort            int32  `json:"nodePort"`
-	TargetPort          int32  `json:"targetPort"`
+	// +optional
+	ImagePullPolicy string `json:"imagePullPolicy,omitempty"`
+	// +optional
+	DeploymentName string `json:"deploymentName,omitempty"`
+	// Replicas may be left unset when a HorizontalPodAutoscaler scales the
+	// Bookstore through its scale subresource.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// +optional
+	ServiceName string `json:"serviceName,omitempty"`
+	// +optional
+	ServiceType   string `json:"serviceType,omitempty"`
+	ContainerPort int32  `json:"containerPort"`
+	NodePort      int32  `json:"nodePort"`
+	// +optional
+	TargetPort int32 `json:"targetPort,omitempty"`
+	// SecretRef names the Secret in the Bookstore namespace that holds the
+	// envAdminUsername, envAdminPassword and envJWTSECRET keys. Defaults to
+	// DefaultSecretName. It is ignored when GenerateSecret is set.
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+	// GenerateSecret makes the controller create a Secret owned by the
+	// Bookstore with a random admin username, admin password and JWT secret
+	// under the keys above, instead of reading them from SecretRef. The
+	// values are only generated again when the RegenerateSecretAnnotation
+	// changes.
+	// +optional
+	GenerateSecret bool `json:"generateSecret,omitempty"`
+	// ConfigMapRef names a ConfigMap in the Bookstore namespace whose
+	// entries are exposed to the bookstore container as environment
+	// variables.
+	// +optional
+	ConfigMapRef *corev1.LocalObjectReference `json:"configMapRef,omitempty"`
+	// DeletionPolicy decides what happens to the Deployment, Service and
+	// generated Secret when the Bookstore is deleted. One of Delete, Orphan
+	// or Retain; defaults to Delete.
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+	// Workload configures the compute resources, probes and termination of
+	// the bookstore container. Unset fields are defaulted.
+	// +optional
+	Workload WorkloadSpec `json:"workload,omitempty"`
+	// PodTemplate is a strategic merge patch of a pod template, applied on
+	// top of the pod template the controller builds for the Deployment. It
+	// adds pod settings the spec has no field for, such as a nodeSelector,
+	// tolerations or extra volumes. It may not change the selector labels of
+	// the Bookstore or remove the bookstore container.
+	// +optional
+	PodTemplate *runtime.RawExtension `json:"podTemplate,omitempty"`
+}
+
+// WorkloadSpec configures the bookstore container of the Deployment.
+type WorkloadSpec struct {
+	// Resources are the compute resources of the bookstore container.
+	// Defaults to DefaultResources.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Probes configures the startup, readiness and liveness probes of the
+	// bookstore container.
+	// +optional
+	Probes ProbesSpec `json:"probes,omitempty"`
+	// TerminationGracePeriodSeconds is how long a pod is given to shut down
+	// once it is asked to, preStop hook included. Defaults to
+	// DefaultTerminationGracePeriodSeconds.
+	// +optional
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+	// PreStopSleepSeconds is how long a terminating pod keeps serving before
+	// the bookstore container gets SIGTERM, so that it is removed from the
+	// Service endpoints first. 0 disables the preStop hook. Defaults to
+	// DefaultPreStopSleepSeconds.
+	// +optional
+	PreStopSleepSeconds *int64 `json:"preStopSleepSeconds,omitempty"`
+}
+
+// ProbesSpec configures the probes of the bookstore container. Every probe
+// checks containerPort: with an HTTP GET of its path if one is set, or by
+// opening a TCP connection otherwise, which only tells that the API is
+// listening.
+type ProbesSpec struct {
+	// StartupPath is the path of the startup probe, which holds off the
+	// other probes until the API has started.
+	// +optional
+	StartupPath string `json:"startupPath,omitempty"`
+	// ReadinessPath is the path of the readiness probe, which decides
+	// whether the pod receives traffic.
+	// +optional
+	ReadinessPath string `json:"readinessPath,omitempty"`
+	// LivenessPath is the path of the liveness probe, which restarts the
+	// container when it fails.
+	// +optional
+	LivenessPath string `json:"livenessPath,omitempty"`
 }
 
 // BookstoreStatus is the status for a Bookstore resource
 type BookstoreStatus struct {
+	// ObservedGeneration is the most recent generation of the Bookstore spec
+	// that the controller has acted upon.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Replicas is the number of pods of the Deployment, as reported through
+	// the scale subresource.
+	Replicas          int32 `json:"replicas,omitempty"`
 	AvailableReplicas int32 `json:"availableReplicas"`
+	// Selector is the label selector of the pods of the Deployment, in the
+	// string form expected by the scale subresource.
+	Selector string `json:"selector,omitempty"`
+	// SecretName is the name of the credentials Secret read by the pods.
+	SecretName string `json:"secretName,omitempty"`
+	// Conditions describe the current state of the Bookstore.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
+// These are the condition types reported in BookstoreStatus.Conditions.
+const (
+	// BookstoreReady means the Deployment has rolled out and all of its
+	// replicas are available.
+	BookstoreReady = "Ready"
+	// BookstoreProgressing means a rollout of the Deployment is in progress.
+	BookstoreProgressing = "Progressing"
+	// BookstoreDegraded means the last sync failed or the rollout is stuck.
+	BookstoreDegraded = "Degraded"
+	// BookstoreResourceConflict means a Deployment or Service named in the
+	// spec already exists and is not managed by this Bookstore, or that other
+	// field managers own some of the fields the Bookstore sets on its
+	// children.
+	BookstoreResourceConflict = "ResourceConflict"
+	// BookstoreSecretMissing means the credentials Secret, or one of the keys
+	// the Bookstore reads from it, does not exist.
+	BookstoreSecretMissing = "SecretMissing"
+	// BookstoreFailed means the last sync failed in a way that only a change
+	// of the spec can fix. The Bookstore is not synced again until its
+	// generation changes.
+	BookstoreFailed = "Failed"
+)
+
+// These are the supported values of BookstoreSpec.DeletionPolicy.
+const (
+	// DeletionPolicyDelete scales the Deployment to zero and then lets the
+	// garbage collector delete the children along with the Bookstore.
+	DeletionPolicyDelete = "Delete"
+	// DeletionPolicyOrphan releases the children untouched, so they keep
+	// running after the Bookstore is gone.
+	DeletionPolicyOrphan = "Orphan"
+	// DeletionPolicyRetain scales the Deployment to zero and then releases
+	// the children, so they are kept but no longer serve.
+	DeletionPolicyRetain = "Retain"
+)
+
+// AdoptAnnotation set to "true" lets a Bookstore adopt an existing
+// Deployment or Service named in its spec that has no controller, instead of
+// reporting a ResourceConflict.
+const AdoptAnnotation = "calico.com/adopt"
+
+// RegenerateSecretAnnotation is set on a Bookstore with GenerateSecret to
+// request new credentials. The Secret is generated again every time the
+// value of the annotation changes, for example to the current time.
+const RegenerateSecretAnnotation = "calico.com/regenerate-secret"
+
+// ConfigHashAnnotation is set by the controller on the pod template of a
+// Bookstore Deployment to a hash of the Secret and ConfigMap data read by its
+// pods. It may not be set by the podTemplate overlay.
+const ConfigHashAnnotation = "calico.com/config-hash"
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // BookstoreList is a list of Bookstore resources
@@ -70,3 +225,15 @@ func (bookstore *Bookstore) GetSelectorLabels() map[string]string {
 		"controller": bookstore.Name + "-customController1",
 	}
 }
+
+// GetCredentialsSecretName returns the name of the Secret the pods of the
+// Bookstore read their credentials from.
+func (bookstore *Bookstore) GetCredentialsSecretName() string {
+	if bookstore.Spec.GenerateSecret {
+		return bookstore.Name + "-credentials"
+	}
+	if ref := bookstore.Spec.SecretRef; ref != nil && ref.Name != "" {
+		return ref.Name
+	}
+	return DefaultSecretName
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+)
+
+// v1beta1 is the hub version: every other version of Bookstore converts to
+// and from it without losing information.
+func addConversionFuncs(scheme *runtime.Scheme) error {
+	if err := scheme.AddConversionFunc((*v1alpha1.Bookstore)(nil), (*Bookstore)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Bookstore_To_v1beta1_Bookstore(a.(*v1alpha1.Bookstore), b.(*Bookstore), scope)
+	}); err != nil {
+		return err
+	}
+	return scheme.AddConversionFunc((*Bookstore)(nil), (*v1alpha1.Bookstore)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_Bookstore_To_v1alpha1_Bookstore(a.(*Bookstore), b.(*v1alpha1.Bookstore), scope)
+	})
+}
+
+// Convert_v1alpha1_Bookstore_To_v1beta1_Bookstore converts a v1alpha1
+// Bookstore to v1beta1.
+func Convert_v1alpha1_Bookstore_To_v1beta1_Bookstore(in *v1alpha1.Bookstore, out *Bookstore, s conversion.Scope) error {
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	out.Spec.Image = ImageSpec{
+		Name:       in.Spec.DeploymentImageName,
+		Tag:        in.Spec.DeploymentImageTag,
+		PullPolicy: corev1.PullPolicy(in.Spec.ImagePullPolicy),
+	}
+	out.Spec.Service = ServiceSpec{
+		Name:       in.Spec.ServiceName,
+		Type:       corev1.ServiceType(in.Spec.ServiceType),
+		NodePort:   in.Spec.NodePort,
+		TargetPort: in.Spec.TargetPort,
+	}
+	out.Spec.Credentials = CredentialsSpec{
+		AdminUsernameKey: in.Spec.EnvAdminUsername,
+		AdminPasswordKey: in.Spec.EnvAdminPassword,
+		JWTSecretKey:     in.Spec.EnvJWTSECRET,
+		SecretRef:        in.Spec.SecretRef.DeepCopy(),
+		Generate:         in.Spec.GenerateSecret,
+	}
+	out.Spec.Workload = WorkloadSpec{
+		DeploymentName:                in.Spec.DeploymentName,
+		ContainerPort:                 in.Spec.ContainerPort,
+		ConfigMapRef:                  in.Spec.ConfigMapRef.DeepCopy(),
+		Resources:                     in.Spec.Workload.Resources.DeepCopy(),
+		Probes:                        ProbesSpec(in.Spec.Workload.Probes),
+		TerminationGracePeriodSeconds: copyInt64(in.Spec.Workload.TerminationGracePeriodSeconds),
+		PreStopSleepSeconds:           copyInt64(in.Spec.Workload.PreStopSleepSeconds),
+		PodTemplate:                   in.Spec.PodTemplate.DeepCopy(),
+	}
+	if in.Spec.Replicas != nil {
+		replicas := *in.Spec.Replicas
+		out.Spec.Workload.Replicas = &replicas
+	}
+	out.Spec.DeletionPolicy = DeletionPolicy(in.Spec.DeletionPolicy)
+
+	out.Status = BookstoreStatus{
+		ObservedGeneration: in.Status.ObservedGeneration,
+		Replicas:           in.Status.Replicas,
+		AvailableReplicas:  in.Status.AvailableReplicas,
+		Selector:           in.Status.Selector,
+		SecretName:         in.Status.SecretName,
+		Conditions:         copyConditions(in.Status.Conditions),
+	}
+	return nil
+}
+
+// Convert_v1beta1_Bookstore_To_v1alpha1_Bookstore converts a v1beta1
+// Bookstore to v1alpha1.
+func Convert_v1beta1_Bookstore_To_v1alpha1_Bookstore(in *Bookstore, out *v1alpha1.Bookstore, s conversion.Scope) error {
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	out.Spec = v1alpha1.BookstoreSpec{
+		EnvAdminUsername:    in.Spec.Credentials.AdminUsernameKey,
+		EnvAdminPassword:    in.Spec.Credentials.AdminPasswordKey,
+		EnvJWTSECRET:        in.Spec.Credentials.JWTSecretKey,
+		DeploymentImageName: in.Spec.Image.Name,
+		DeploymentImageTag:  in.Spec.Image.Tag,
+		ImagePullPolicy:     string(in.Spec.Image.PullPolicy),
+		DeploymentName:      in.Spec.Workload.DeploymentName,
+		ServiceName:         in.Spec.Service.Name,
+		ServiceType:         string(in.Spec.Service.Type),
+		ContainerPort:       in.Spec.Workload.ContainerPort,
+		NodePort:            in.Spec.Service.NodePort,
+		TargetPort:          in.Spec.Service.TargetPort,
+		SecretRef:           in.Spec.Credentials.SecretRef.DeepCopy(),
+		GenerateSecret:      in.Spec.Credentials.Generate,
+		ConfigMapRef:        in.Spec.Workload.ConfigMapRef.DeepCopy(),
+		DeletionPolicy:      string(in.Spec.DeletionPolicy),
+		Workload: v1alpha1.WorkloadSpec{
+			Resources:                     in.Spec.Workload.Resources.DeepCopy(),
+			Probes:                        v1alpha1.ProbesSpec(in.Spec.Workload.Probes),
+			TerminationGracePeriodSeconds: copyInt64(in.Spec.Workload.TerminationGracePeriodSeconds),
+			PreStopSleepSeconds:           copyInt64(in.Spec.Workload.PreStopSleepSeconds),
+		},
+		PodTemplate: in.Spec.Workload.PodTemplate.DeepCopy(),
+	}
+	if in.Spec.Workload.Replicas != nil {
+		replicas := *in.Spec.Workload.Replicas
+		out.Spec.Replicas = &replicas
+	}
+
+	out.Status = v1alpha1.BookstoreStatus{
+		ObservedGeneration: in.Status.ObservedGeneration,
+		Replicas:           in.Status.Replicas,
+		AvailableReplicas:  in.Status.AvailableReplicas,
+		Selector:           in.Status.Selector,
+		SecretName:         in.Status.SecretName,
+		Conditions:         copyConditions(in.Status.Conditions),
+	}
+	return nil
+}
+
+func copyConditions(in []metav1.Condition) []metav1.Condition {
+	if in == nil {
+		return nil
+	}
+	out := make([]metav1.Condition, len(in))
+	copy(out, in)
+	return out
+}
+
+func copyInt64(in *int64) *int64 {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	return &out
+}
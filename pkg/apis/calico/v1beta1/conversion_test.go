@@ -0,0 +1,234 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/ptr"
+
+	"k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+)
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	utilruntime.Must(AddToScheme(scheme))
+	return scheme
+}
+
+// checkPopulated fails the test if value, or a field of a Bookstore type it
+// holds, is left at its zero value, so that a field added to the spec or
+// status cannot be left out of the round trip. Types of other packages, such
+// as ResourceRequirements, are converted whole and only need to be set.
+func checkPopulated(t *testing.T, path string, value reflect.Value) {
+	t.Helper()
+	if value.IsZero() {
+		t.Errorf("%s is not set", path)
+		return
+	}
+	if value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct || !strings.HasPrefix(value.Type().PkgPath(), "k8s.io/sample-controller/pkg/apis/calico/") {
+		return
+	}
+	for i := 0; i < value.NumField(); i++ {
+		if field := value.Type().Field(i); field.IsExported() {
+			checkPopulated(t, path+"."+field.Name, value.Field(i))
+		}
+	}
+}
+
+func testObjectMeta() metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:        "test",
+		Namespace:   metav1.NamespaceDefault,
+		UID:         "test-uid",
+		Generation:  3,
+		Labels:      map[string]string{"team": "books"},
+		Annotations: map[string]string{v1alpha1.AdoptAnnotation: "true"},
+		Finalizers:  []string{"calico.com/deletion-policy"},
+	}
+}
+
+func testResources() *corev1.ResourceRequirements {
+	return &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+	}
+}
+
+func testPodTemplate() *runtime.RawExtension {
+	return &runtime.RawExtension{Raw: []byte(`{"spec":{"nodeSelector":{"disktype":"ssd"}}}`)}
+}
+
+func testConditions() []metav1.Condition {
+	return []metav1.Condition{{
+		Type:               v1alpha1.BookstoreReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: 3,
+		LastTransitionTime: metav1.NewTime(time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		Reason:             "RolloutComplete",
+		Message:            "2 of 2 replicas available",
+	}}
+}
+
+// TestRoundTripFromV1alpha1 checks that a v1alpha1 Bookstore with every
+// field set survives a conversion to v1beta1 and back.
+func TestRoundTripFromV1alpha1(t *testing.T) {
+	scheme := newScheme(t)
+	in := &v1alpha1.Bookstore{
+		ObjectMeta: testObjectMeta(),
+		Spec: v1alpha1.BookstoreSpec{
+			EnvAdminUsername:    "username",
+			EnvAdminPassword:    "password",
+			EnvJWTSECRET:        "jwt",
+			DeploymentImageName: "bookstore",
+			DeploymentImageTag:  "v2",
+			ImagePullPolicy:     string(corev1.PullAlways),
+			DeploymentName:      "test-deployment",
+			Replicas:            ptr.To[int32](2),
+			ServiceName:         "test-service",
+			ServiceType:         string(corev1.ServiceTypeNodePort),
+			ContainerPort:       8081,
+			NodePort:            30001,
+			TargetPort:          8082,
+			SecretRef:           &corev1.LocalObjectReference{Name: "env-secrets"},
+			GenerateSecret:      true,
+			ConfigMapRef:        &corev1.LocalObjectReference{Name: "env-config"},
+			DeletionPolicy:      v1alpha1.DeletionPolicyRetain,
+			Workload: v1alpha1.WorkloadSpec{
+				Resources: testResources(),
+				Probes: v1alpha1.ProbesSpec{
+					StartupPath:   "/startup",
+					ReadinessPath: "/ready",
+					LivenessPath:  "/live",
+				},
+				TerminationGracePeriodSeconds: ptr.To[int64](45),
+				PreStopSleepSeconds:           ptr.To[int64](10),
+			},
+			PodTemplate: testPodTemplate(),
+		},
+		Status: v1alpha1.BookstoreStatus{
+			ObservedGeneration: 3,
+			Replicas:           2,
+			AvailableReplicas:  2,
+			Selector:           "app=test-app,controller=test-customController1",
+			SecretName:         "test-credentials",
+			Conditions:         testConditions(),
+		},
+	}
+
+	checkPopulated(t, "spec", reflect.ValueOf(in.Spec))
+	checkPopulated(t, "status", reflect.ValueOf(in.Status))
+
+	hub := &Bookstore{}
+	if err := scheme.Convert(in, hub, nil); err != nil {
+		t.Fatalf("error converting to v1beta1: %v", err)
+	}
+	out := &v1alpha1.Bookstore{}
+	if err := scheme.Convert(hub, out, nil); err != nil {
+		t.Fatalf("error converting back to v1alpha1: %v", err)
+	}
+	if diff := cmp.Diff(in, out); diff != "" {
+		t.Errorf("round trip changed the Bookstore (-want +got):\n%s", diff)
+	}
+}
+
+// TestRoundTripFromV1beta1 checks that a v1beta1 Bookstore with every field
+// set survives a conversion to v1alpha1 and back.
+func TestRoundTripFromV1beta1(t *testing.T) {
+	scheme := newScheme(t)
+	in := &Bookstore{
+		ObjectMeta: testObjectMeta(),
+		Spec: BookstoreSpec{
+			Image: ImageSpec{
+				Name:       "bookstore",
+				Tag:        "v2",
+				PullPolicy: corev1.PullAlways,
+			},
+			Service: ServiceSpec{
+				Name:       "test-service",
+				Type:       corev1.ServiceTypeNodePort,
+				NodePort:   30001,
+				TargetPort: 8082,
+			},
+			Credentials: CredentialsSpec{
+				AdminUsernameKey: "username",
+				AdminPasswordKey: "password",
+				JWTSecretKey:     "jwt",
+				SecretRef:        &corev1.LocalObjectReference{Name: "env-secrets"},
+				Generate:         true,
+			},
+			Workload: WorkloadSpec{
+				DeploymentName: "test-deployment",
+				Replicas:       ptr.To[int32](2),
+				ContainerPort:  8081,
+				ConfigMapRef:   &corev1.LocalObjectReference{Name: "env-config"},
+				Resources:      testResources(),
+				Probes: ProbesSpec{
+					StartupPath:   "/startup",
+					ReadinessPath: "/ready",
+					LivenessPath:  "/live",
+				},
+				TerminationGracePeriodSeconds: ptr.To[int64](45),
+				PreStopSleepSeconds:           ptr.To[int64](10),
+				PodTemplate:                   testPodTemplate(),
+			},
+			DeletionPolicy: DeletionPolicyRetain,
+		},
+		Status: BookstoreStatus{
+			ObservedGeneration: 3,
+			Replicas:           2,
+			AvailableReplicas:  2,
+			Selector:           "app=test-app,controller=test-customController1",
+			SecretName:         "test-credentials",
+			Conditions:         testConditions(),
+		},
+	}
+
+	checkPopulated(t, "spec", reflect.ValueOf(in.Spec))
+	checkPopulated(t, "status", reflect.ValueOf(in.Status))
+
+	spoke := &v1alpha1.Bookstore{}
+	if err := scheme.Convert(in, spoke, nil); err != nil {
+		t.Fatalf("error converting to v1alpha1: %v", err)
+	}
+	out := &Bookstore{}
+	if err := scheme.Convert(spoke, out, nil); err != nil {
+		t.Fatalf("error converting back to v1beta1: %v", err)
+	}
+	if diff := cmp.Diff(in, out); diff != "" {
+		t.Errorf("round trip changed the Bookstore (-want +got):\n%s", diff)
+	}
+}
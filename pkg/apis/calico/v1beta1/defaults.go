@@ -0,0 +1,78 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+)
+
+// DefaultSecretName is the credentials Secret read by Bookstores that do not
+// set a secretRef.
+const DefaultSecretName = "env-secrets"
+
+func addDefaultingFuncs(scheme *runtime.Scheme) error {
+	return RegisterDefaults(scheme)
+}
+
+// SetDefaults_Bookstore fills in the spec fields of a Bookstore that can be
+// derived from its name or from other fields of the spec. It mirrors the
+// defaults of v1alpha1.
+func SetDefaults_Bookstore(obj *Bookstore) {
+	// Objects created with generateName have no name until after admission,
+	// so the child names can only be derived once it is known.
+	if obj.Name != "" {
+		if obj.Spec.Workload.DeploymentName == "" {
+			obj.Spec.Workload.DeploymentName = obj.Name + "-deployment"
+		}
+		if obj.Spec.Service.Name == "" {
+			obj.Spec.Service.Name = obj.Name + "-service"
+		}
+	}
+	if !obj.Spec.Credentials.Generate && (obj.Spec.Credentials.SecretRef == nil || obj.Spec.Credentials.SecretRef.Name == "") {
+		obj.Spec.Credentials.SecretRef = &corev1.LocalObjectReference{Name: DefaultSecretName}
+	}
+	if obj.Spec.Service.TargetPort == 0 {
+		obj.Spec.Service.TargetPort = obj.Spec.Workload.ContainerPort
+	}
+	if obj.Spec.Image.PullPolicy == "" {
+		obj.Spec.Image.PullPolicy = corev1.PullIfNotPresent
+	}
+	if obj.Spec.Service.Type == "" {
+		obj.Spec.Service.Type = corev1.ServiceTypeClusterIP
+	}
+	if obj.Spec.DeletionPolicy == "" {
+		obj.Spec.DeletionPolicy = DeletionPolicyDelete
+	}
+}
+
+// SetDefaults_WorkloadSpec mirrors v1alpha1.SetDefaults_WorkloadSpec.
+func SetDefaults_WorkloadSpec(obj *WorkloadSpec) {
+	if obj.Resources == nil {
+		obj.Resources = v1alpha1.DefaultResources()
+	}
+	if obj.TerminationGracePeriodSeconds == nil {
+		period := v1alpha1.DefaultTerminationGracePeriodSeconds
+		obj.TerminationGracePeriodSeconds = &period
+	}
+	if obj.PreStopSleepSeconds == nil {
+		sleep := v1alpha1.DefaultPreStopSleepSeconds
+		obj.PreStopSleepSeconds = &sleep
+	}
+}
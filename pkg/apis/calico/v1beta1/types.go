@@ -0,0 +1,207 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// +genclient
+// +genclient:method=GetScale,verb=get,subresource=scale,result=k8s.io/api/autoscaling/v1.Scale
+// +genclient:method=UpdateScale,verb=update,subresource=scale,input=k8s.io/api/autoscaling/v1.Scale,result=k8s.io/api/autoscaling/v1.Scale
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Bookstore is a specification for a Bookstore resource
+type Bookstore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BookstoreSpec   `json:"spec"`
+	Status BookstoreStatus `json:"status"`
+}
+
+// BookstoreSpec is the spec for a Bookstore resource
+type BookstoreSpec struct {
+	// Image is the container image running the bookstore API.
+	Image ImageSpec `json:"image"`
+	// Service configures the Service in front of the bookstore pods.
+	Service ServiceSpec `json:"service"`
+	// Credentials selects the Secret keys holding the admin credentials and
+	// the JWT signing secret.
+	Credentials CredentialsSpec `json:"credentials"`
+	// Workload configures the Deployment running the bookstore API.
+	Workload WorkloadSpec `json:"workload"`
+	// DeletionPolicy decides what happens to the children of the Bookstore
+	// when it is deleted. Defaults to Delete.
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// DeletionPolicy decides what happens to the Deployment, Service and
+// generated Secret of a Bookstore when it is deleted.
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete scales the Deployment to zero and then lets the
+	// garbage collector delete the children along with the Bookstore.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyOrphan releases the children untouched, so they keep
+	// running after the Bookstore is gone.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+	// DeletionPolicyRetain scales the Deployment to zero and then releases
+	// the children, so they are kept but no longer serve.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
+// ImageSpec describes the container image of a Bookstore.
+type ImageSpec struct {
+	Name string `json:"name"`
+	Tag  string `json:"tag"`
+	// +optional
+	PullPolicy corev1.PullPolicy `json:"pullPolicy,omitempty"`
+}
+
+// ServiceSpec describes the Service exposing a Bookstore.
+type ServiceSpec struct {
+	// +optional
+	Name string `json:"name,omitempty"`
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
+	// +optional
+	TargetPort int32 `json:"targetPort,omitempty"`
+}
+
+// CredentialsSpec names the credentials Secret and the keys of it that are
+// exposed to the bookstore container.
+type CredentialsSpec struct {
+	AdminUsernameKey string `json:"adminUsernameKey"`
+	AdminPasswordKey string `json:"adminPasswordKey"`
+	JWTSecretKey     string `json:"jwtSecretKey"`
+	// SecretRef names the Secret in the Bookstore namespace that holds the
+	// keys above. Defaults to DefaultSecretName. It is ignored when Generate
+	// is set.
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+	// Generate makes the controller create a Secret owned by the Bookstore
+	// with random credentials under the keys above, instead of reading them
+	// from SecretRef.
+	// +optional
+	Generate bool `json:"generate,omitempty"`
+}
+
+// WorkloadSpec describes the Deployment running a Bookstore.
+type WorkloadSpec struct {
+	// +optional
+	DeploymentName string `json:"deploymentName,omitempty"`
+	// Replicas may be left unset when a HorizontalPodAutoscaler scales the
+	// Bookstore through its scale subresource.
+	// +optional
+	Replicas      *int32 `json:"replicas,omitempty"`
+	ContainerPort int32  `json:"containerPort"`
+	// ConfigMapRef names a ConfigMap in the Bookstore namespace whose
+	// entries are exposed to the bookstore container as environment
+	// variables.
+	// +optional
+	ConfigMapRef *corev1.LocalObjectReference `json:"configMapRef,omitempty"`
+	// Resources are the compute resources of the bookstore container.
+	// Defaults to v1alpha1.DefaultResources.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Probes configures the startup, readiness and liveness probes of the
+	// bookstore container.
+	// +optional
+	Probes ProbesSpec `json:"probes,omitempty"`
+	// TerminationGracePeriodSeconds is how long a pod is given to shut down
+	// once it is asked to, preStop hook included.
+	// +optional
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+	// PreStopSleepSeconds is how long a terminating pod keeps serving before
+	// the bookstore container gets SIGTERM. 0 disables the preStop hook.
+	// +optional
+	PreStopSleepSeconds *int64 `json:"preStopSleepSeconds,omitempty"`
+	// PodTemplate is a strategic merge patch of a pod template, applied on
+	// top of the pod template the controller builds for the Deployment.
+	// +optional
+	PodTemplate *runtime.RawExtension `json:"podTemplate,omitempty"`
+}
+
+// ProbesSpec configures the probes of the bookstore container. Every probe
+// checks containerPort: with an HTTP GET of its path if one is set, or by
+// opening a TCP connection otherwise.
+type ProbesSpec struct {
+	// +optional
+	StartupPath string `json:"startupPath,omitempty"`
+	// +optional
+	ReadinessPath string `json:"readinessPath,omitempty"`
+	// +optional
+	LivenessPath string `json:"livenessPath,omitempty"`
+}
+
+// BookstoreStatus is the status for a Bookstore resource
+type BookstoreStatus struct {
+	// ObservedGeneration is the most recent generation of the Bookstore spec
+	// that the controller has acted upon.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Replicas is the number of pods of the Deployment, as reported through
+	// the scale subresource.
+	Replicas          int32 `json:"replicas,omitempty"`
+	AvailableReplicas int32 `json:"availableReplicas"`
+	// Selector is the label selector of the pods of the Deployment, in the
+	// string form expected by the scale subresource.
+	Selector string `json:"selector,omitempty"`
+	// SecretName is the name of the credentials Secret read by the pods.
+	SecretName string `json:"secretName,omitempty"`
+	// Conditions describe the current state of the Bookstore.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BookstoreList is a list of Bookstore resources
+type BookstoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Bookstore `json:"items"`
+}
+
+func (bookstore *Bookstore) GetSelectorLabels() map[string]string {
+	return map[string]string{
+		"app":        bookstore.Name + "-app",
+		"controller": bookstore.Name + "-customController1",
+	}
+}
+
+// GetCredentialsSecretName returns the name of the Secret the pods of the
+// Bookstore read their credentials from.
+func (bookstore *Bookstore) GetCredentialsSecretName() string {
+	if bookstore.Spec.Credentials.Generate {
+		return bookstore.Name + "-credentials"
+	}
+	if ref := bookstore.Spec.Credentials.SecretRef; ref != nil && ref.Name != "" {
+		return ref.Name
+	}
+	return DefaultSecretName
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation validates Bookstore resources.
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+)
+
+var supportedServiceTypes = []corev1.ServiceType{
+	corev1.ServiceTypeClusterIP,
+	corev1.ServiceTypeNodePort,
+	corev1.ServiceTypeLoadBalancer,
+}
+
+var supportedDeletionPolicies = []string{
+	v1alpha1.DeletionPolicyDelete,
+	v1alpha1.DeletionPolicyOrphan,
+	v1alpha1.DeletionPolicyRetain,
+}
+
+var supportedPullPolicies = []corev1.PullPolicy{
+	corev1.PullAlways,
+	corev1.PullIfNotPresent,
+	corev1.PullNever,
+}
+
+// ValidateBookstore validates a Bookstore. nodePortRange is the range the
+// cluster allocates node ports from.
+func ValidateBookstore(bookstore *v1alpha1.Bookstore, nodePortRange utilnet.PortRange) field.ErrorList {
+	allErrs := ValidateBookstoreSpec(&bookstore.Spec, nodePortRange, field.NewPath("spec"))
+	allErrs = append(allErrs, ValidatePodTemplate(bookstore, field.NewPath("spec", "podTemplate"))...)
+	return allErrs
+}
+
+// ValidateBookstoreSpec validates the spec of a Bookstore.
+func ValidateBookstoreSpec(spec *v1alpha1.BookstoreSpec, nodePortRange utilnet.PortRange, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateName(spec.DeploymentName, utilvalidation.IsDNS1123Subdomain, fldPath.Child("deploymentName"))...)
+	allErrs = append(allErrs, validateName(spec.ServiceName, utilvalidation.IsDNS1035Label, fldPath.Child("serviceName"))...)
+
+	if spec.DeploymentImageName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("deploymentImageName"), ""))
+	}
+	if !slices.Contains(supportedPullPolicies, corev1.PullPolicy(spec.ImagePullPolicy)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("imagePullPolicy"), spec.ImagePullPolicy, supportedPullPolicies))
+	}
+
+	if spec.Replicas != nil && *spec.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), *spec.Replicas, "must be greater than or equal to 0"))
+	}
+
+	switch {
+	case spec.GenerateSecret:
+		// The generated Secret is named after the Bookstore and secretRef
+		// is ignored.
+	case spec.SecretRef == nil:
+		allErrs = append(allErrs, field.Required(fldPath.Child("secretRef"), ""))
+	default:
+		allErrs = append(allErrs, validateName(spec.SecretRef.Name, utilvalidation.IsDNS1123Subdomain, fldPath.Child("secretRef", "name"))...)
+	}
+	if spec.ConfigMapRef != nil {
+		allErrs = append(allErrs, validateName(spec.ConfigMapRef.Name, utilvalidation.IsDNS1123Subdomain, fldPath.Child("configMapRef", "name"))...)
+	}
+	allErrs = append(allErrs, validateSecretKey(spec.EnvAdminUsername, fldPath.Child("envAdminUsername"))...)
+	allErrs = append(allErrs, validateSecretKey(spec.EnvAdminPassword, fldPath.Child("envAdminPassword"))...)
+	allErrs = append(allErrs, validateSecretKey(spec.EnvJWTSECRET, fldPath.Child("envJWTSECRET"))...)
+
+	allErrs = append(allErrs, validatePort(spec.ContainerPort, fldPath.Child("containerPort"))...)
+	allErrs = append(allErrs, validatePort(spec.TargetPort, fldPath.Child("targetPort"))...)
+
+	serviceType := corev1.ServiceType(spec.ServiceType)
+	if !slices.Contains(supportedServiceTypes, serviceType) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("serviceType"), spec.ServiceType, supportedServiceTypes))
+	}
+	if spec.NodePort != 0 {
+		switch {
+		case serviceType != corev1.ServiceTypeNodePort && serviceType != corev1.ServiceTypeLoadBalancer:
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("nodePort"), fmt.Sprintf("may not be set when serviceType is %s", spec.ServiceType)))
+		case !nodePortRange.Contains(int(spec.NodePort)):
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("nodePort"), spec.NodePort, fmt.Sprintf("provided port is not in the valid range. The range of valid ports is %s", nodePortRange.String())))
+		}
+	}
+
+	if !slices.Contains(supportedDeletionPolicies, spec.DeletionPolicy) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("deletionPolicy"), spec.DeletionPolicy, supportedDeletionPolicies))
+	}
+
+	allErrs = append(allErrs, validateWorkload(&spec.Workload, fldPath.Child("workload"))...)
+
+	return allErrs
+}
+
+// validateWorkload validates the resources, probes and termination settings
+// of the bookstore container.
+func validateWorkload(workload *v1alpha1.WorkloadSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if workload.Resources != nil {
+		allErrs = append(allErrs, validateResources(workload.Resources, fldPath.Child("resources"))...)
+	}
+
+	probesPath := fldPath.Child("probes")
+	allErrs = append(allErrs, validateProbePath(workload.Probes.StartupPath, probesPath.Child("startupPath"))...)
+	allErrs = append(allErrs, validateProbePath(workload.Probes.ReadinessPath, probesPath.Child("readinessPath"))...)
+	allErrs = append(allErrs, validateProbePath(workload.Probes.LivenessPath, probesPath.Child("livenessPath"))...)
+
+	if period := workload.TerminationGracePeriodSeconds; period != nil && *period < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("terminationGracePeriodSeconds"), *period, "must be greater than or equal to 0"))
+	}
+	if sleep := workload.PreStopSleepSeconds; sleep != nil {
+		switch period := workload.TerminationGracePeriodSeconds; {
+		case *sleep < 0:
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("preStopSleepSeconds"), *sleep, "must be greater than or equal to 0"))
+		case period != nil && *sleep > *period:
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("preStopSleepSeconds"), *sleep, "must be less than or equal to terminationGracePeriodSeconds"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateResources checks that no quantity is negative and that no request
+// exceeds its limit.
+func validateResources(resources *corev1.ResourceRequirements, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for name, quantity := range resources.Limits {
+		if quantity.Sign() < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("limits").Key(string(name)), quantity.String(), "must be greater than or equal to 0"))
+		}
+	}
+	for name, quantity := range resources.Requests {
+		if quantity.Sign() < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("requests").Key(string(name)), quantity.String(), "must be greater than or equal to 0"))
+			continue
+		}
+		if limit, ok := resources.Limits[name]; ok && quantity.Cmp(limit) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("requests").Key(string(name)), quantity.String(), fmt.Sprintf("must be less than or equal to %s limit of %s", name, limit.String())))
+		}
+	}
+	return allErrs
+}
+
+// validateProbePath checks that a probe path, if set, is an absolute HTTP
+// path.
+func validateProbePath(path string, fldPath *field.Path) field.ErrorList {
+	if path == "" || strings.HasPrefix(path, "/") {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(fldPath, path, "must be an absolute path starting with '/'")}
+}
+
+// validateName checks that name is set and passes the given name validator.
+func validateName(name string, isValid func(string) []string, fldPath *field.Path) field.ErrorList {
+	if name == "" {
+		return field.ErrorList{field.Required(fldPath, "")}
+	}
+	allErrs := field.ErrorList{}
+	for _, msg := range isValid(name) {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, msg))
+	}
+	return allErrs
+}
+
+// validateSecretKey checks that key is set and is a valid Secret data key.
+func validateSecretKey(key string, fldPath *field.Path) field.ErrorList {
+	if key == "" {
+		return field.ErrorList{field.Required(fldPath, "")}
+	}
+	allErrs := field.ErrorList{}
+	for _, msg := range utilvalidation.IsConfigMapKey(key) {
+		allErrs = append(allErrs, field.Invalid(fldPath, key, msg))
+	}
+	return allErrs
+}
+
+// validatePort checks that port is a valid port number.
+func validatePort(port int32, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, msg := range utilvalidation.IsValidPortNum(int(port)) {
+		allErrs = append(allErrs, field.Invalid(fldPath, port, msg))
+	}
+	return allErrs
+}
+
+// ValidatePodTemplate validates the podTemplate overlay of a Bookstore. It
+// patches a pod template holding only the selector labels and the bookstore
+// container with the overlay, and checks that the patch applies, keeps both
+// and leaves the annotations the controller sets alone. The selector labels depend on the name of the Bookstore, which is why
+// this is not part of ValidateBookstoreSpec.
+func ValidatePodTemplate(bookstore *v1alpha1.Bookstore, fldPath *field.Path) field.ErrorList {
+	overlay := bookstore.Spec.PodTemplate
+	if overlay == nil || len(overlay.Raw) == 0 {
+		return nil
+	}
+
+	selector := bookstore.GetSelectorLabels()
+	base := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: selector},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: bookstore.Spec.DeploymentName}},
+		},
+	}
+	original, err := json.Marshal(base)
+	if err != nil {
+		return field.ErrorList{field.InternalError(fldPath, err)}
+	}
+	patched, err := strategicpatch.StrategicMergePatch(original, overlay.Raw, corev1.PodTemplateSpec{})
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, string(overlay.Raw), fmt.Sprintf("must be a strategic merge patch of a pod template: %v", err))}
+	}
+	var template corev1.PodTemplateSpec
+	if err := json.Unmarshal(patched, &template); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, string(overlay.Raw), fmt.Sprintf("must be a strategic merge patch of a pod template: %v", err))}
+	}
+
+	allErrs := field.ErrorList{}
+	for _, key := range sets.List(sets.KeySet(selector)) {
+		if value, ok := template.Labels[key]; !ok || value != selector[key] {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("metadata", "labels").Key(key), fmt.Sprintf("may not change the selector label %s=%s", key, selector[key])))
+		}
+	}
+	if _, ok := template.Annotations[v1alpha1.ConfigHashAnnotation]; ok {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("metadata", "annotations").Key(v1alpha1.ConfigHashAnnotation), "is set by the controller"))
+	}
+	if name := bookstore.Spec.DeploymentName; name != "" {
+		if !slices.ContainsFunc(template.Spec.Containers, func(c corev1.Container) bool { return c.Name == name }) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("spec", "containers"), fmt.Sprintf("may not remove or rename the bookstore container %q", name)))
+		}
+	}
+	return allErrs
+}
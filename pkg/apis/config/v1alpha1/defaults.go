@@ -0,0 +1,66 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func addDefaultingFuncs(scheme *runtime.Scheme) error {
+	return RegisterDefaults(scheme)
+}
+
+// SetDefaults_BookstoreControllerConfiguration fills in the fields left
+// unset with the values the controller used before it was configurable.
+func SetDefaults_BookstoreControllerConfiguration(obj *BookstoreControllerConfiguration) {
+	if obj.Workers == 0 {
+		obj.Workers = 2
+	}
+	if obj.ResyncPeriod.Duration == 0 {
+		obj.ResyncPeriod.Duration = 30 * time.Second
+	}
+}
+
+// SetDefaults_RateLimiterConfiguration defaults the rate limiter to the one
+// of the sample-controller.
+func SetDefaults_RateLimiterConfiguration(obj *RateLimiterConfiguration) {
+	if obj.BaseDelay.Duration == 0 {
+		obj.BaseDelay.Duration = 5 * time.Millisecond
+	}
+	if obj.MaxDelay.Duration == 0 {
+		obj.MaxDelay.Duration = 1000 * time.Second
+	}
+	if obj.QPS == 0 {
+		obj.QPS = 50
+	}
+	if obj.Burst == 0 {
+		obj.Burst = 300
+	}
+}
+
+// SetDefaults_ClientConnectionConfiguration defaults the clients to the
+// rate limits of client-go.
+func SetDefaults_ClientConnectionConfiguration(obj *ClientConnectionConfiguration) {
+	if obj.QPS == 0 {
+		obj.QPS = 5
+	}
+	if obj.Burst == 0 {
+		obj.Burst = 10
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BookstoreControllerConfiguration configures the Bookstore controller. It
+// is read from the file passed with -config.
+type BookstoreControllerConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Workers is the number of Bookstores synced concurrently.
+	Workers int32 `json:"workers,omitempty"`
+	// ResyncPeriod is how often the informers resync every object, which
+	// queues every Bookstore again.
+	ResyncPeriod metav1.Duration `json:"resyncPeriod,omitempty"`
+	// RateLimiter configures how Bookstores are requeued.
+	RateLimiter RateLimiterConfiguration `json:"rateLimiter"`
+	// ClientConnection configures the connection to the API server.
+	ClientConnection ClientConnectionConfiguration `json:"clientConnection"`
+	// Verbosity is the klog verbosity of the logs, as set by -v.
+	Verbosity int32 `json:"verbosity,omitempty"`
+}
+
+// RateLimiterConfiguration configures the rate limiter of the workqueue. A
+// Bookstore is requeued after the larger of its exponential backoff and the
+// delay imposed by the overall bucket.
+type RateLimiterConfiguration struct {
+	// BaseDelay is the backoff after the first failed sync of a Bookstore. It
+	// doubles on every further failure.
+	BaseDelay metav1.Duration `json:"baseDelay,omitempty"`
+	// MaxDelay caps the backoff of a Bookstore.
+	MaxDelay metav1.Duration `json:"maxDelay,omitempty"`
+	// QPS is the rate at which the bucket lets Bookstores be requeued.
+	QPS float32 `json:"qps,omitempty"`
+	// Burst is the size of the bucket.
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// ClientConnectionConfiguration configures the clients of the controller.
+type ClientConnectionConfiguration struct {
+	// QPS is the rate of requests to the API server allowed by each client.
+	QPS float32 `json:"qps,omitempty"`
+	// Burst is the number of requests a client may send at once above QPS.
+	Burst int32 `json:"burst,omitempty"`
+}
@@ -0,0 +1,88 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by deepcopy-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BookstoreControllerConfiguration) DeepCopyInto(out *BookstoreControllerConfiguration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ResyncPeriod = in.ResyncPeriod
+	out.RateLimiter = in.RateLimiter
+	out.ClientConnection = in.ClientConnection
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BookstoreControllerConfiguration.
+func (in *BookstoreControllerConfiguration) DeepCopy() *BookstoreControllerConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(BookstoreControllerConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BookstoreControllerConfiguration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientConnectionConfiguration) DeepCopyInto(out *ClientConnectionConfiguration) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientConnectionConfiguration.
+func (in *ClientConnectionConfiguration) DeepCopy() *ClientConnectionConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientConnectionConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimiterConfiguration) DeepCopyInto(out *RateLimiterConfiguration) {
+	*out = *in
+	out.BaseDelay = in.BaseDelay
+	out.MaxDelay = in.MaxDelay
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimiterConfiguration.
+func (in *RateLimiterConfiguration) DeepCopy() *RateLimiterConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimiterConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
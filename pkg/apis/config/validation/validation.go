@@ -0,0 +1,73 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation validates the controller configuration.
+package validation
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"k8s.io/sample-controller/pkg/apis/config/v1alpha1"
+)
+
+// ValidateBookstoreControllerConfiguration validates a defaulted controller
+// configuration.
+func ValidateBookstoreControllerConfiguration(cfg *v1alpha1.BookstoreControllerConfiguration) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if cfg.Workers < 1 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("workers"), cfg.Workers, "must be at least 1"))
+	}
+	if cfg.ResyncPeriod.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("resyncPeriod"), cfg.ResyncPeriod.Duration.String(), "must be positive"))
+	}
+	if cfg.Verbosity < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("verbosity"), cfg.Verbosity, "must not be negative"))
+	}
+	allErrs = append(allErrs, validateRateLimiter(&cfg.RateLimiter, field.NewPath("rateLimiter"))...)
+	allErrs = append(allErrs, validateClientConnection(&cfg.ClientConnection, field.NewPath("clientConnection"))...)
+	return allErrs
+}
+
+func validateRateLimiter(cfg *v1alpha1.RateLimiterConfiguration, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if cfg.BaseDelay.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("baseDelay"), cfg.BaseDelay.Duration.String(), "must be positive"))
+	}
+	if cfg.MaxDelay.Duration < cfg.BaseDelay.Duration {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxDelay"), cfg.MaxDelay.Duration.String(), "must not be less than baseDelay"))
+	}
+	if cfg.QPS <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("qps"), cfg.QPS, "must be positive"))
+	}
+	if cfg.Burst < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("burst"), cfg.Burst, "must be at least 1"))
+	}
+	return allErrs
+}
+
+func validateClientConnection(cfg *v1alpha1.ClientConnectionConfiguration, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if cfg.QPS <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("qps"), cfg.QPS, "must be positive"))
+	}
+	if cfg.Burst < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("burst"), cfg.Burst, "must be at least 1"))
+	}
+	return allErrs
+}
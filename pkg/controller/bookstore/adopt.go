@@ -0,0 +1,104 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bookstore
+
+import (
+	"encoding/json"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	samplev1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+)
+
+// adoptionPatch is called for a child named in the Bookstore spec that is
+// not controlled by the Bookstore. If the Bookstore opted into adoption with
+// the AdoptAnnotation and the child has no controller, it returns a
+// strategic merge patch that makes the Bookstore its controller. Otherwise
+// it records an ErrResourceExists event and returns a resourceConflictError.
+// A child that cannot be reconciled to the spec once adopted, such as a
+// Deployment selecting other pods, is refused with the reason unadoptable
+// gives, unless it is empty.
+func (r *reconciler) adoptionPatch(bookstore *samplev1alpha1.Bookstore, child metav1.Object, unadoptable string) ([]byte, error) {
+	if !adoptable(bookstore, child, unadoptable) {
+		err := &resourceConflictError{name: child.GetName()}
+		if bookstore.Annotations[samplev1alpha1.AdoptAnnotation] == "true" {
+			err.detail = unadoptable
+		}
+		r.recorder.Event(bookstore, corev1.EventTypeWarning, ErrResourceExists, err.Error())
+		return nil, err
+	}
+
+	// ownerReferences are merged by UID, so the patch adds our reference
+	// and keeps the ones already there. The UID of the child makes the
+	// patch fail if the child was replaced since we read it.
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"ownerReferences": []metav1.OwnerReference{
+				*metav1.NewControllerRef(bookstore, samplev1alpha1.SchemeGroupVersion.WithKind("Bookstore")),
+			},
+			"uid": child.GetUID(),
+		},
+	})
+}
+
+// adoptable reports whether bookstore may adopt child: it opted into adoption
+// and child has no controller and no reason, given by unadoptable, not to be
+// adopted.
+func adoptable(bookstore *samplev1alpha1.Bookstore, child metav1.Object, unadoptable string) bool {
+	return bookstore.Annotations[samplev1alpha1.AdoptAnnotation] == "true" && metav1.GetControllerOf(child) == nil && unadoptable == ""
+}
+
+// childConflicts reports whether a child named in the spec of bookstore
+// exists without being controlled by it and cannot be adopted. A Bookstore
+// that failed with ErrResourceExists is synced again once no child
+// conflicts, which neither deleting the child nor annotating the Bookstore
+// for adoption signals through its generation.
+func (r *reconciler) childConflicts(bookstore *samplev1alpha1.Bookstore) bool {
+	conflicts := func(child metav1.Object, unadoptable string) bool {
+		return !metav1.IsControlledBy(child, bookstore) && !adoptable(bookstore, child, unadoptable)
+	}
+	if deployment, err := r.deploymentsLister.Deployments(bookstore.Namespace).Get(bookstore.Spec.DeploymentName); err == nil && conflicts(deployment, deploymentUnadoptable(bookstore, deployment)) {
+		return true
+	}
+	if service, err := r.serviceLister.Services(bookstore.Namespace).Get(bookstore.Spec.ServiceName); err == nil && conflicts(service, "") {
+		return true
+	}
+	if bookstore.Spec.GenerateSecret {
+		// A generated Secret is never adopted.
+		if secret, err := r.secretsLister.Secrets(bookstore.Namespace).Get(bookstore.GetCredentialsSecretName()); err == nil && !metav1.IsControlledBy(secret, bookstore) {
+			return true
+		}
+	}
+	return false
+}
+
+// deploymentUnadoptable explains why deployment cannot be adopted by
+// bookstore, or returns an empty string if it can. The selector of a
+// Deployment is immutable, so one that does not select exactly the pods of
+// the Bookstore would fail every apply of the Bookstore pod template.
+func deploymentUnadoptable(bookstore *samplev1alpha1.Bookstore, deployment *appsv1.Deployment) string {
+	want := labels.SelectorFromSet(bookstore.GetSelectorLabels())
+	got, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil || deployment.Spec.Selector == nil || got.String() != want.String() {
+		return fmt.Sprintf("its selector %s does not match the Bookstore pods %s", metav1.FormatLabelSelector(deployment.Spec.Selector), want)
+	}
+	return ""
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bookstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+
+	samplev1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+)
+
+// applyOptions are used for the server-side applies of the children of a
+// Bookstore. Conflicts are not forced: a field that another manager set to a
+// different value, such as the replicas of a Deployment scaled by a
+// HorizontalPodAutoscaler or an image changed by hand, is left to it and
+// reported, rather than fought over on every sync.
+var applyOptions = metav1.ApplyOptions{FieldManager: FieldManager}
+
+// forceApplyOptions are used where the controller is the only authority: for
+// the status of a Bookstore, and for the first apply to a child the Bookstore
+// was asked to adopt, which takes it over from its previous managers.
+var forceApplyOptions = metav1.ApplyOptions{FieldManager: FieldManager, Force: true}
+
+// childApplyOptions returns the options to apply a child that exists as
+// live. A child the controller never applied has been adopted, so its first
+// apply forces conflicts.
+func childApplyOptions(live metav1.Object) metav1.ApplyOptions {
+	for _, entry := range live.GetManagedFields() {
+		if entry.Manager == FieldManager && entry.Operation == metav1.ManagedFieldsOperationApply {
+			return applyOptions
+		}
+	}
+	return forceApplyOptions
+}
+
+// fieldConflictError is returned when a child of a Bookstore cannot be
+// applied because other field managers set some of the fields the Bookstore
+// applies to different values.
+type fieldConflictError struct {
+	kind string
+	name string
+	// message is the message of the conflict returned by the API server,
+	// which lists the fields in dispute and their managers.
+	message string
+}
+
+func (e *fieldConflictError) Error() string {
+	return fmt.Sprintf(MessageFieldConflict, e.kind, e.name, e.message)
+}
+
+// isApplyConflict reports whether err is an apply refused because other
+// field managers own some of the applied fields.
+func isApplyConflict(err error) bool {
+	var status apierrors.APIStatus
+	if !apierrors.IsConflict(err) || !errors.As(err, &status) || status.Status().Details == nil {
+		return false
+	}
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type == metav1.CauseTypeFieldManagerConflict {
+			return true
+		}
+	}
+	return false
+}
+
+// applyDeployment applies desired, the Deployment of bookstore, which
+// exists as live or is nil if it does not exist yet.
+func (r *reconciler) applyDeployment(bookstore *samplev1alpha1.Bookstore, desired *appsv1ac.DeploymentApplyConfiguration, live *appsv1.Deployment) (*appsv1.Deployment, error) {
+	client := r.kubeclientset.AppsV1().Deployments(bookstore.Namespace)
+	options := applyOptions
+	if live != nil {
+		options = childApplyOptions(live)
+	}
+	return applyChild(r, bookstore, "Deployment", *desired.Name,
+		func() (*appsv1.Deployment, error) {
+			return client.Apply(context.TODO(), desired, options)
+		},
+		func(patch []byte) error {
+			_, err := client.Patch(context.TODO(), *desired.Name, types.StrategicMergePatchType, patch, reclaimOptions)
+			return err
+		},
+		desired.ObjectMetaApplyConfiguration, map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": desired.Spec.Template.Labels},
+			},
+		})
+}
+
+// applyService is the Service counterpart of applyDeployment.
+func (r *reconciler) applyService(bookstore *samplev1alpha1.Bookstore, desired *corev1ac.ServiceApplyConfiguration, live *corev1.Service) (*corev1.Service, error) {
+	client := r.kubeclientset.CoreV1().Services(bookstore.Namespace)
+	options := applyOptions
+	if live != nil {
+		options = childApplyOptions(live)
+	}
+	return applyChild(r, bookstore, "Service", *desired.Name,
+		func() (*corev1.Service, error) {
+			return client.Apply(context.TODO(), desired, options)
+		},
+		func(patch []byte) error {
+			_, err := client.Patch(context.TODO(), *desired.Name, types.StrategicMergePatchType, patch, reclaimOptions)
+			return err
+		},
+		desired.ObjectMetaApplyConfiguration, map[string]interface{}{"selector": desired.Spec.Selector})
+}
+
+// applySecret is the generated Secret counterpart of applyDeployment.
+func (r *reconciler) applySecret(bookstore *samplev1alpha1.Bookstore, desired *corev1ac.SecretApplyConfiguration, live *corev1.Secret) (*corev1.Secret, error) {
+	client := r.kubeclientset.CoreV1().Secrets(bookstore.Namespace)
+	options := applyOptions
+	if live != nil {
+		options = childApplyOptions(live)
+	}
+	return applyChild(r, bookstore, "Secret", *desired.Name,
+		func() (*corev1.Secret, error) {
+			return client.Apply(context.TODO(), desired, options)
+		},
+		func(patch []byte) error {
+			_, err := client.Patch(context.TODO(), *desired.Name, types.StrategicMergePatchType, patch, reclaimOptions)
+			return err
+		},
+		desired.ObjectMetaApplyConfiguration, nil)
+}
+
+// reclaimOptions are used for the patches that take back the fields the
+// controller must own. A patch is not an apply: the fields it changes are
+// taken from their managers without a conflict.
+var reclaimOptions = metav1.PatchOptions{FieldManager: FieldManager}
+
+// applyChild applies a child of bookstore named name with apply. If other
+// managers own some of the applied fields, the fields the controller must
+// own, as described by ownedFieldsPatch of meta and spec, are taken back
+// with reclaim, and the apply is tried again. The rest of the fields in
+// dispute are left to their managers, and reported with a FieldConflict
+// event and a fieldConflictError.
+func applyChild[T any](r *reconciler, bookstore *samplev1alpha1.Bookstore, kind, name string, apply func() (T, error), reclaim func(patch []byte) error, meta *metav1ac.ObjectMetaApplyConfiguration, spec map[string]interface{}) (T, error) {
+	applied, err := apply()
+	if !isApplyConflict(err) {
+		return applied, err
+	}
+	patch, err := ownedFieldsPatch(meta, spec)
+	if err != nil {
+		return applied, err
+	}
+	if err := reclaim(patch); err != nil {
+		return applied, err
+	}
+	applied, err = apply()
+	if isApplyConflict(err) {
+		conflict := &fieldConflictError{kind: kind, name: name, message: err.Error()}
+		r.recorder.Event(bookstore, corev1.EventTypeWarning, FieldConflict, conflict.Error())
+		return applied, conflict
+	}
+	return applied, err
+}
+
+// ownedFieldsPatch returns a strategic merge patch of the fields of a child
+// that the controller must own whoever else manages the child: the labels
+// and owner references of meta, which tie it to the Bookstore, and spec,
+// which holds the labels that select the pods of the Bookstore, if the child
+// has any. The patch takes these fields from any other manager.
+func ownedFieldsPatch(meta *metav1ac.ObjectMetaApplyConfiguration, spec map[string]interface{}) ([]byte, error) {
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels":          meta.Labels,
+			"ownerReferences": meta.OwnerReferences,
+		},
+	}
+	if spec != nil {
+		patch["spec"] = spec
+	}
+	return json.Marshal(patch)
+}
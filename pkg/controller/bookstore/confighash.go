@@ -0,0 +1,65 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bookstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+
+	samplev1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+)
+
+// ConfigHashAnnotation is set on the pod template of a Bookstore Deployment
+// to a hash of the Secret and ConfigMap data read by its pods. Changing that
+// data changes the pod template, which rolls the pods.
+const ConfigHashAnnotation = samplev1alpha1.ConfigHashAnnotation
+
+// podConfigHash returns a hash of the data the pods of a Bookstore read from
+// its credentials Secret and ConfigMap. Only the Secret keys the Bookstore
+// reads are hashed, so unrelated keys can change without a rollout. Both the
+// data and the binaryData of the ConfigMap are hashed, since a pod template
+// overlay may mount it as a volume. configMap is nil if the Bookstore
+// references none or it does not exist.
+func podConfigHash(bookstore *samplev1alpha1.Bookstore, secret *corev1.Secret, configMap *corev1.ConfigMap) string {
+	hasher := sha256.New()
+	for _, key := range []string{bookstore.Spec.EnvAdminUsername, bookstore.Spec.EnvAdminPassword, bookstore.Spec.EnvJWTSECRET} {
+		fmt.Fprintf(hasher, "secret %q=%q\n", key, secret.Data[key])
+	}
+	if configMap != nil {
+		for _, key := range sortedKeys(configMap.Data) {
+			fmt.Fprintf(hasher, "configMap %q=%q\n", key, configMap.Data[key])
+		}
+		for _, key := range sortedKeys(configMap.BinaryData) {
+			fmt.Fprintf(hasher, "configMap binary %q=%q\n", key, configMap.BinaryData[key])
+		}
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// sortedKeys returns the keys of m in increasing order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
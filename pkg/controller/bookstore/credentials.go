@@ -0,0 +1,149 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bookstore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/klog/v2"
+
+	samplev1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+)
+
+// syncGeneratedSecret applies the credentials Secret of a Bookstore with
+// spec.generateSecret set, and returns it. Values are generated for keys the
+// Secret lacks, and for every key when the RegenerateSecretAnnotation of the
+// Bookstore is set and differs from the one recorded on the Secret. Existing
+// values are never changed otherwise.
+func (r *reconciler) syncGeneratedSecret(ctx context.Context, bookstore *samplev1alpha1.Bookstore) (*corev1.Secret, error) {
+	logger := klog.LoggerWithValues(klog.FromContext(ctx), "bookstore", klog.KObj(bookstore))
+	name := bookstore.GetCredentialsSecretName()
+	generation := bookstore.Annotations[samplev1alpha1.RegenerateSecretAnnotation]
+
+	secret, err := r.secretsLister.Secrets(bookstore.Namespace).Get(name)
+	if errors.IsNotFound(err) {
+		// The cache may not have caught up with a Secret applied by a
+		// previous sync yet. Generating values for it would silently rotate
+		// the credentials, so its absence is confirmed with the API server.
+		secret, err = r.kubeclientset.CoreV1().Secrets(bookstore.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			secret, err = nil, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if secret != nil && !metav1.IsControlledBy(secret, bookstore) {
+		err := &resourceConflictError{name: secret.Name}
+		r.recorder.Event(bookstore, corev1.EventTypeWarning, ErrResourceExists, err.Error())
+		return nil, err
+	}
+
+	// Removing the annotation does not request new values, only setting it
+	// to a new one does.
+	regenerate := secret != nil && generation != "" && secret.Annotations[samplev1alpha1.RegenerateSecretAnnotation] != generation
+	var existing map[string][]byte
+	if secret != nil && !regenerate {
+		existing = secret.Data
+	}
+	data, err := generateCredentials(bookstore, existing)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := corev1ac.Secret(name, bookstore.Namespace).
+		WithLabels(bookstore.GetSelectorLabels()).
+		WithOwnerReferences(controllerRef(bookstore)).
+		WithType(corev1.SecretTypeOpaque).
+		WithData(data)
+	if generation != "" {
+		desired.WithAnnotations(map[string]string{samplev1alpha1.RegenerateSecretAnnotation: generation})
+	}
+	drifted, err := secretDrifted(desired, secret)
+	if err != nil || !drifted {
+		return secret, err
+	}
+
+	logger.V(4).Info("Apply generated credentials secret", "secret", klog.KRef(bookstore.Namespace, name), "regenerate", regenerate)
+	applied, err := r.applySecret(bookstore, desired, secret)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case secret == nil:
+		r.recorder.Eventf(bookstore, corev1.EventTypeNormal, SecretGenerated, MessageSecretGenerated, applied.Name)
+	case regenerate:
+		r.recorder.Eventf(bookstore, corev1.EventTypeNormal, SecretRegenerated, MessageSecretRegenerated, applied.Name)
+	}
+	return applied, nil
+}
+
+// generateCredentials returns the credential keys of the Bookstore with the
+// values from existing, generating the ones existing lacks.
+func generateCredentials(bookstore *samplev1alpha1.Bookstore, existing map[string][]byte) (map[string][]byte, error) {
+	generators := []struct {
+		key      string
+		generate func() (string, error)
+	}{
+		{bookstore.Spec.EnvAdminUsername, func() (string, error) {
+			suffix, err := randomBytes(4)
+			return "admin-" + hex.EncodeToString(suffix), err
+		}},
+		{bookstore.Spec.EnvAdminPassword, func() (string, error) { return randomString(24) }},
+		{bookstore.Spec.EnvJWTSECRET, func() (string, error) { return randomString(48) }},
+	}
+
+	data := make(map[string][]byte, len(generators))
+	for _, g := range generators {
+		if value, ok := existing[g.key]; ok {
+			data[g.key] = value
+			continue
+		}
+		value, err := g.generate()
+		if err != nil {
+			return nil, fmt.Errorf("generating %q for secret %q: %w", g.key, bookstore.GetCredentialsSecretName(), err)
+		}
+		data[g.key] = []byte(value)
+	}
+	return data, nil
+}
+
+// randomString returns n random bytes encoded as unpadded URL-safe base64.
+func randomString(n int) (string, error) {
+	b, err := randomBytes(n)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// randomBytes returns n bytes read from a cryptographically secure source.
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bookstore
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+// deploymentDrifted reports whether the fields the controller applied to
+// live differ from desired, either because the Bookstore changed or because
+// another actor took them over. live may be nil if it does not exist yet.
+func deploymentDrifted(desired *appsv1ac.DeploymentApplyConfiguration, live *appsv1.Deployment) (bool, error) {
+	if live == nil {
+		return true, nil
+	}
+	applied, err := appsv1ac.ExtractDeployment(live, FieldManager)
+	if err != nil {
+		return false, err
+	}
+	return !equality.Semantic.DeepEqual(desired, applied), nil
+}
+
+// serviceDrifted is the Service counterpart of deploymentDrifted.
+func serviceDrifted(desired *corev1ac.ServiceApplyConfiguration, live *corev1.Service) (bool, error) {
+	if live == nil {
+		return true, nil
+	}
+	applied, err := corev1ac.ExtractService(live, FieldManager)
+	if err != nil {
+		return false, err
+	}
+	return !equality.Semantic.DeepEqual(desired, applied), nil
+}
+
+// secretDrifted is the Secret counterpart of deploymentDrifted.
+func secretDrifted(desired *corev1ac.SecretApplyConfiguration, live *corev1.Secret) (bool, error) {
+	if live == nil {
+		return true, nil
+	}
+	applied, err := corev1ac.ExtractSecret(live, FieldManager)
+	if err != nil {
+		return false, err
+	}
+	return !equality.Semantic.DeepEqual(desired, applied), nil
+}
+
+// labelSelector returns the apply configuration of selector. The selector of
+// a Deployment is immutable, so an existing Deployment, which may have been
+// adopted, is applied with the selector it already has.
+func labelSelector(selector *metav1.LabelSelector) *metav1ac.LabelSelectorApplyConfiguration {
+	applied := metav1ac.LabelSelector()
+	if selector == nil {
+		return applied
+	}
+	if selector.MatchLabels != nil {
+		applied.WithMatchLabels(selector.MatchLabels)
+	}
+	for _, requirement := range selector.MatchExpressions {
+		applied.WithMatchExpressions(metav1ac.LabelSelectorRequirement().
+			WithKey(requirement.Key).
+			WithOperator(requirement.Operator).
+			WithValues(requirement.Values...))
+	}
+	return applied
+}
+
+// serviceRecreateReason explains why the live Service cannot be changed in
+// place to the desired type, or returns an empty string if it can.
+func serviceRecreateReason(desiredType corev1.ServiceType, live *corev1.Service) string {
+	if desiredType == live.Spec.Type {
+		return ""
+	}
+	if desiredType == corev1.ServiceTypeExternalName || live.Spec.Type == corev1.ServiceTypeExternalName {
+		return fmt.Sprintf("service type cannot change from %s to %s in place", live.Spec.Type, desiredType)
+	}
+	if serviceTypeHasNodePorts(live.Spec.Type) && !serviceTypeHasNodePorts(desiredType) {
+		return fmt.Sprintf("service type %s cannot keep the node ports allocated for type %s", desiredType, live.Spec.Type)
+	}
+	return ""
+}
+
+// serviceTypeHasNodePorts reports whether Services of the given type are
+// allocated node ports.
+func serviceTypeHasNodePorts(serviceType corev1.ServiceType) bool {
+	return serviceType == corev1.ServiceTypeNodePort || serviceType == corev1.ServiceTypeLoadBalancer
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bookstore
+
+import (
+	"errors"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"k8s.io/sample-controller/pkg/metrics"
+)
+
+// ErrorClass tells the caller of Reconcile how to requeue a Bookstore whose
+// reconcile failed.
+type ErrorClass int
+
+const (
+	// ErrorTransient is an error that may go away on its own, such as a
+	// network failure. The Bookstore is requeued with exponential backoff.
+	ErrorTransient ErrorClass = iota
+	// ErrorTerminal is an error that retrying cannot fix. The Bookstore is
+	// marked Failed and not retried until its generation changes or, for a
+	// child owned by someone else, until the child is deleted or adoptable.
+	ErrorTerminal
+	// ErrorConflict is a write based on a stale version of an object. The
+	// Bookstore is requeued at once, to be synced against the latest
+	// version.
+	ErrorConflict
+	// ErrorRateLimited is a request rejected by the API server because of
+	// too many requests. The Bookstore is requeued after the delay the API
+	// server asked for.
+	ErrorRateLimited
+)
+
+// ClassifyError returns the class of err, which must not be nil.
+func ClassifyError(err error) ErrorClass {
+	switch {
+	case isTerminal(err):
+		return ErrorTerminal
+	case apierrors.IsConflict(err):
+		return ErrorConflict
+	case apierrors.IsTooManyRequests(err):
+		return ErrorRateLimited
+	default:
+		return ErrorTransient
+	}
+}
+
+// isTerminal reports whether err cannot be fixed by retrying: the spec is
+// invalid, names a child resource owned by someone else, or leads to a child
+// the API server refuses as invalid. A child owned by someone else can also be
+// resolved by deleting it or opting into its adoption, after which the
+// Bookstore is synced again without a spec change.
+func isTerminal(err error) bool {
+	var conflict *resourceConflictError
+	return isInvalidSpec(err) || errors.As(err, &conflict) || apierrors.IsInvalid(err)
+}
+
+// RetryAfter returns the delay the API server asked for before retrying
+// after err, and whether it asked for one.
+func RetryAfter(err error) (time.Duration, bool) {
+	seconds, ok := apierrors.SuggestsClientDelay(err)
+	if !ok || seconds <= 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// syncResult classifies the outcome of a sync for metrics.ObserveSync.
+func syncResult(err error) string {
+	if err == nil {
+		return metrics.SyncResultSuccess
+	}
+	switch ClassifyError(err) {
+	case ErrorTerminal:
+		return metrics.SyncResultInvalidSpec
+	case ErrorConflict:
+		return metrics.SyncResultConflict
+	case ErrorRateLimited:
+		return metrics.SyncResultRateLimited
+	default:
+		return metrics.SyncResultTransientError
+	}
+}
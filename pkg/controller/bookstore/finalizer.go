@@ -0,0 +1,235 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bookstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	samplev1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+)
+
+// BookstoreFinalizer is added to every Bookstore so that the controller can
+// enforce its deletion policy before the Bookstore goes away.
+const BookstoreFinalizer = "calico.com/deletion-policy"
+
+// ensureFinalizer adds BookstoreFinalizer to the Bookstore if it is missing.
+func (r *reconciler) ensureFinalizer(bookstore *samplev1alpha1.Bookstore) error {
+	if slices.Contains(bookstore.Finalizers, BookstoreFinalizer) {
+		return nil
+	}
+	return r.patchFinalizers(bookstore, append(slices.Clone(bookstore.Finalizers), BookstoreFinalizer))
+}
+
+// finalizeBookstore enforces the deletion policy of a Bookstore that is being
+// deleted and releases BookstoreFinalizer once that is done. It returns
+// without error while waiting for the Deployments to scale down; their
+// status updates requeue the Bookstore.
+func (r *reconciler) finalizeBookstore(ctx context.Context, bookstore *samplev1alpha1.Bookstore) error {
+	logger := klog.LoggerWithValues(klog.FromContext(ctx), "bookstore", klog.KObj(bookstore))
+	if !slices.Contains(bookstore.Finalizers, BookstoreFinalizer) {
+		return nil
+	}
+
+	policy := bookstore.Spec.DeletionPolicy
+	if policy == samplev1alpha1.DeletionPolicyDelete || policy == samplev1alpha1.DeletionPolicyRetain {
+		scaledDown, err := r.scaleToZero(ctx, bookstore)
+		if err != nil || !scaledDown {
+			return err
+		}
+	}
+	if policy == samplev1alpha1.DeletionPolicyOrphan || policy == samplev1alpha1.DeletionPolicyRetain {
+		if err := r.orphanChildren(ctx, bookstore); err != nil {
+			return err
+		}
+	}
+
+	logger.V(4).Info("Release finalizer", "deletionPolicy", policy)
+	return r.patchFinalizers(bookstore, slices.DeleteFunc(slices.Clone(bookstore.Finalizers), func(finalizer string) bool {
+		return finalizer == BookstoreFinalizer
+	}))
+}
+
+// scaleToZero scales the Deployments controlled by the Bookstore to zero
+// replicas and reports whether all of their pods are gone.
+func (r *reconciler) scaleToZero(ctx context.Context, bookstore *samplev1alpha1.Bookstore) (bool, error) {
+	logger := klog.LoggerWithValues(klog.FromContext(ctx), "bookstore", klog.KObj(bookstore))
+	deployments, err := r.controlledDeployments(bookstore)
+	if err != nil {
+		return false, err
+	}
+
+	scaledDown := true
+	for _, deployment := range deployments {
+		if deployment.DeletionTimestamp != nil {
+			continue
+		}
+		if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 0 {
+			logger.V(4).Info("Scale deployment to zero before deletion", "deployment", klog.KObj(deployment))
+			deployment, err = r.kubeclientset.AppsV1().Deployments(bookstore.Namespace).Patch(context.TODO(), deployment.Name, types.MergePatchType, []byte(`{"spec":{"replicas":0}}`), metav1.PatchOptions{})
+			if errors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return false, err
+			}
+		}
+		scaledDown = scaledDown && deploymentScaledDown(deployment)
+	}
+	return scaledDown, nil
+}
+
+// deploymentScaledDown reports whether the Deployment controller has seen
+// the latest spec of deployment and removed all of its pods.
+func deploymentScaledDown(deployment *appsv1.Deployment) bool {
+	return deployment.Status.ObservedGeneration >= deployment.Generation && deployment.Status.Replicas == 0
+}
+
+// orphanChildren removes the controller reference of the Bookstore from its
+// Deployments, Services and generated Secret, so that the garbage collector
+// leaves them in place when the Bookstore is deleted.
+func (r *reconciler) orphanChildren(ctx context.Context, bookstore *samplev1alpha1.Bookstore) error {
+	logger := klog.LoggerWithValues(klog.FromContext(ctx), "bookstore", klog.KObj(bookstore))
+
+	deployments, err := r.controlledDeployments(bookstore)
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments {
+		logger.V(4).Info("Orphan deployment", "deployment", klog.KObj(deployment))
+		_, err = r.kubeclientset.AppsV1().Deployments(bookstore.Namespace).Patch(context.TODO(), deployment.Name, types.StrategicMergePatchType, orphanPatch(bookstore, deployment), metav1.PatchOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	services, err := r.controlledServices(bookstore)
+	if err != nil {
+		return err
+	}
+	for _, service := range services {
+		logger.V(4).Info("Orphan service", "service", klog.KObj(service))
+		_, err = r.kubeclientset.CoreV1().Services(bookstore.Namespace).Patch(context.TODO(), service.Name, types.StrategicMergePatchType, orphanPatch(bookstore, service), metav1.PatchOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if !bookstore.Spec.GenerateSecret {
+		return nil
+	}
+	secret, err := r.secretsLister.Secrets(bookstore.Namespace).Get(bookstore.GetCredentialsSecretName())
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && metav1.IsControlledBy(secret, bookstore) {
+		logger.V(4).Info("Orphan secret", "secret", klog.KObj(secret))
+		_, err = r.kubeclientset.CoreV1().Secrets(bookstore.Namespace).Patch(context.TODO(), secret.Name, types.StrategicMergePatchType, orphanPatch(bookstore, secret), metav1.PatchOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// controlledDeployments returns the Deployments controlled by the Bookstore,
+// sorted by name: the one named in its spec, which may not be labeled yet if
+// it was just adopted, and every Deployment labeled as managed by the
+// controller, such as one left to prune after a rename.
+func (r *reconciler) controlledDeployments(bookstore *samplev1alpha1.Bookstore) ([]*appsv1.Deployment, error) {
+	lister := r.deploymentsLister.Deployments(bookstore.Namespace)
+	deployments, err := lister.List(labels.SelectorFromSet(labels.Set{ManagedByLabel: FieldManager}))
+	if err != nil {
+		return nil, err
+	}
+	named, err := lister.Get(bookstore.Spec.DeploymentName)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil && named.Labels[ManagedByLabel] != FieldManager {
+		deployments = append(deployments, named)
+	}
+	deployments = slices.DeleteFunc(deployments, func(deployment *appsv1.Deployment) bool {
+		return !metav1.IsControlledBy(deployment, bookstore)
+	})
+	slices.SortFunc(deployments, func(a, b *appsv1.Deployment) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return deployments, nil
+}
+
+// controlledServices is controlledDeployments for Services.
+func (r *reconciler) controlledServices(bookstore *samplev1alpha1.Bookstore) ([]*corev1.Service, error) {
+	lister := r.serviceLister.Services(bookstore.Namespace)
+	services, err := lister.List(labels.SelectorFromSet(labels.Set{ManagedByLabel: FieldManager}))
+	if err != nil {
+		return nil, err
+	}
+	named, err := lister.Get(bookstore.Spec.ServiceName)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil && named.Labels[ManagedByLabel] != FieldManager {
+		services = append(services, named)
+	}
+	services = slices.DeleteFunc(services, func(service *corev1.Service) bool {
+		return !metav1.IsControlledBy(service, bookstore)
+	})
+	slices.SortFunc(services, func(a, b *corev1.Service) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return services, nil
+}
+
+// orphanPatch returns a strategic merge patch that deletes the owner
+// reference to the Bookstore from child, in the same way the garbage
+// collector orphans dependents. The UID guards against patching a child that
+// was recreated in the meantime.
+func orphanPatch(bookstore *samplev1alpha1.Bookstore, child metav1.Object) []byte {
+	return []byte(fmt.Sprintf(`{"metadata":{"ownerReferences":[{"$patch":"delete","uid":%q}],"uid":%q}}`, bookstore.UID, child.GetUID()))
+}
+
+// patchFinalizers replaces the finalizers of the Bookstore. The
+// resourceVersion makes the patch fail with a conflict if the list changed
+// since the Bookstore was read.
+func (r *reconciler) patchFinalizers(bookstore *samplev1alpha1.Bookstore, finalizers []string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers":      finalizers,
+			"resourceVersion": bookstore.ResourceVersion,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.sampleclientset.CalicoV1alpha1().Bookstores(bookstore.Namespace).Patch(context.TODO(), bookstore.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,365 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fixture
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/managedfields"
+	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	appsv1client "k8s.io/client-go/kubernetes/typed/apps/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/openapi"
+	"k8s.io/client-go/openapi/openapitest"
+	core "k8s.io/client-go/testing"
+)
+
+// typeConverter returns the schema of the Kubernetes types, so that field
+// managers track their associative lists, such as containers and ports, by
+// key like the API server does. The schema of the openapitest package may
+// predate fields the controller sets, which are kept rather than refused.
+var typeConverter = sync.OnceValues(func() (managedfields.TypeConverter, error) {
+	return openapi.NewTypeConverter(openapitest.NewEmbeddedFileClient(), true)
+})
+
+// newFieldManager returns a field manager for the objects of kind.
+func newFieldManager(kind schema.GroupVersionKind) (*managedfields.FieldManager, error) {
+	converter, err := typeConverter()
+	if err != nil {
+		return nil, err
+	}
+	return managedfields.NewDefaultFieldManager(converter, scheme.Scheme, noDefaulter{}, scheme.Scheme, kind, kind.GroupVersion(), "", nil)
+}
+
+// noDefaulter leaves objects alone: the objects of a fixture are stored as
+// the test wrote them.
+type noDefaulter struct{}
+
+func (noDefaulter) Default(runtime.Object) {}
+
+// applyAction is the action of an apply patch, along with the options of the
+// apply that the fake clientset would drop.
+type applyAction struct {
+	core.PatchActionImpl
+	options metav1.ApplyOptions
+}
+
+func (a applyAction) DeepCopy() core.Action {
+	return applyAction{
+		PatchActionImpl: a.PatchActionImpl.DeepCopy().(core.PatchActionImpl),
+		options:         *a.options.DeepCopy(),
+	}
+}
+
+// patchAction is the action of any other patch, along with its options.
+type patchAction struct {
+	core.PatchActionImpl
+	options metav1.PatchOptions
+}
+
+func (a patchAction) DeepCopy() core.Action {
+	return patchAction{
+		PatchActionImpl: a.PatchActionImpl.DeepCopy().(core.PatchActionImpl),
+		options:         *a.options.DeepCopy(),
+	}
+}
+
+// fieldManagedReaction returns a reactor that serves the patches of the
+// Kubernetes client like the API server, recording in managedFields which
+// manager owns which field of the objects in tracker. An apply patch is
+// merged into the object, or creates it, and its manager owns the fields it
+// applied. Fields owned by another manager are only taken over by a forced
+// apply; otherwise the apply fails with a conflict. Any other patch takes
+// the fields it changes from their previous managers.
+func fieldManagedReaction(tracker core.ObjectTracker) core.ReactionFunc {
+	var lock sync.Mutex
+	managers := map[schema.GroupVersionKind]*managedfields.FieldManager{}
+	fieldManager := func(kind schema.GroupVersionKind) (*managedfields.FieldManager, error) {
+		if manager, ok := managers[kind]; ok {
+			return manager, nil
+		}
+		manager, err := newFieldManager(kind)
+		if err == nil {
+			managers[kind] = manager
+		}
+		return manager, err
+	}
+	patchObject := core.ObjectReaction(tracker)
+
+	return func(action core.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "" {
+			// Patches of subresources are merged without tracking
+			// field ownership.
+			switch action := action.(type) {
+			case applyAction:
+				return patchObject(action.PatchActionImpl)
+			case patchAction:
+				return patchObject(action.PatchActionImpl)
+			}
+			return false, nil, nil
+		}
+		lock.Lock()
+		defer lock.Unlock()
+
+		switch action := action.(type) {
+		case applyAction:
+			if action.options.FieldManager == "" {
+				return true, nil, errors.NewBadRequest("PatchOptions.fieldManager is required for apply requests")
+			}
+			gvr, ns, name := action.GetResource(), action.GetNamespace(), action.GetName()
+			applied := &unstructured.Unstructured{}
+			if err := applied.UnmarshalJSON(action.GetPatch()); err != nil {
+				return true, nil, errors.NewBadRequest(err.Error())
+			}
+			kind := applied.GroupVersionKind()
+			manager, err := fieldManager(kind)
+			if err != nil {
+				return true, nil, err
+			}
+
+			live, err := tracker.Get(gvr, ns, name)
+			exists := err == nil
+			if errors.IsNotFound(err) {
+				live, err = scheme.Scheme.New(kind)
+			}
+			if err != nil {
+				return true, nil, err
+			}
+			live.GetObjectKind().SetGroupVersionKind(kind)
+
+			obj, err := manager.Apply(live, applied, action.options.FieldManager, action.options.Force)
+			if err != nil {
+				return true, nil, err
+			}
+			if exists {
+				err = tracker.Update(gvr, obj, ns)
+			} else {
+				err = tracker.Create(gvr, obj, ns)
+			}
+			if err != nil {
+				return true, nil, err
+			}
+			obj, err = tracker.Get(gvr, ns, name)
+			return true, obj, err
+		case patchAction:
+			gvr, ns, name := action.GetResource(), action.GetNamespace(), action.GetName()
+			live, err := tracker.Get(gvr, ns, name)
+			if err != nil {
+				return true, nil, err
+			}
+			kinds, _, err := scheme.Scheme.ObjectKinds(live)
+			if err != nil {
+				return true, nil, err
+			}
+			manager, err := fieldManager(kinds[0])
+			if err != nil {
+				return true, nil, err
+			}
+			_, patched, err := patchObject(action.PatchActionImpl)
+			if err != nil {
+				return true, nil, err
+			}
+			live.GetObjectKind().SetGroupVersionKind(kinds[0])
+			patched.GetObjectKind().SetGroupVersionKind(kinds[0])
+			name = action.options.FieldManager
+			if name == "" {
+				// The API server names the manager of a patch without
+				// one after the user agent of the client.
+				name = "unknown"
+			}
+			obj, err := manager.Update(live, patched, name)
+			if err != nil {
+				return true, nil, err
+			}
+			if err := tracker.Update(gvr, obj, ns); err != nil {
+				return true, nil, err
+			}
+			return true, obj, nil
+		default:
+			return false, nil, nil
+		}
+	}
+}
+
+// Apply returns live as the API server stores it once manager has applied
+// the apply configuration obj to it, forcing conflicts. live is nil for an
+// object that does not exist yet. It lets a test start from children whose
+// managedFields tell who set which field.
+func Apply(t *testing.T, live runtime.Object, manager string, obj interface{}) runtime.Object {
+	t.Helper()
+	data, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("error encoding apply configuration: %v", err)
+	}
+	applied := &unstructured.Unstructured{}
+	if err := applied.UnmarshalJSON(data); err != nil {
+		t.Fatalf("error decoding apply configuration: %v", err)
+	}
+	kind := applied.GroupVersionKind()
+	fieldManager, err := newFieldManager(kind)
+	if err != nil {
+		t.Fatalf("error building field manager for %v: %v", kind, err)
+	}
+
+	if live == nil {
+		if live, err = scheme.Scheme.New(kind); err != nil {
+			t.Fatalf("error creating %v: %v", kind, err)
+		}
+	} else {
+		live = live.DeepCopyObject()
+	}
+	live.GetObjectKind().SetGroupVersionKind(kind)
+
+	result, err := fieldManager.Apply(live, applied, manager, true)
+	if err != nil {
+		t.Fatalf("error applying %v as %s: %v", kind, manager, err)
+	}
+	return result
+}
+
+// kubeClient is the Kubernetes clientset handed to the controller under
+// test. The Apply and Patch methods of the fake clientset drop their
+// options, so kubeClient passes them on with the action, for
+// fieldManagedReaction to know the field manager of the patch and whether
+// it is forced.
+type kubeClient struct {
+	*k8sfake.Clientset
+}
+
+var _ kubernetes.Interface = kubeClient{}
+
+func (c kubeClient) AppsV1() appsv1client.AppsV1Interface {
+	return appsV1Client{c.Clientset.AppsV1(), c.Clientset}
+}
+
+func (c kubeClient) CoreV1() corev1client.CoreV1Interface {
+	return coreV1Client{c.Clientset.CoreV1(), c.Clientset}
+}
+
+type appsV1Client struct {
+	appsv1client.AppsV1Interface
+	fake *k8sfake.Clientset
+}
+
+func (c appsV1Client) Deployments(namespace string) appsv1client.DeploymentInterface {
+	return deploymentClient{c.AppsV1Interface.Deployments(namespace), c.fake, namespace}
+}
+
+type coreV1Client struct {
+	corev1client.CoreV1Interface
+	fake *k8sfake.Clientset
+}
+
+func (c coreV1Client) Services(namespace string) corev1client.ServiceInterface {
+	return serviceClient{c.CoreV1Interface.Services(namespace), c.fake, namespace}
+}
+
+func (c coreV1Client) Secrets(namespace string) corev1client.SecretInterface {
+	return secretClient{c.CoreV1Interface.Secrets(namespace), c.fake, namespace}
+}
+
+type deploymentClient struct {
+	appsv1client.DeploymentInterface
+	fake      *k8sfake.Clientset
+	namespace string
+}
+
+func (c deploymentClient) Apply(ctx context.Context, deployment *appsv1ac.DeploymentApplyConfiguration, opts metav1.ApplyOptions) (*appsv1.Deployment, error) {
+	return invokeApply(c.fake, DeploymentsResource, c.namespace, deployment.Name, deployment, opts, &appsv1.Deployment{})
+}
+
+func (c deploymentClient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*appsv1.Deployment, error) {
+	return invokePatch(c.fake, DeploymentsResource, c.namespace, name, pt, data, opts, subresources, &appsv1.Deployment{})
+}
+
+type serviceClient struct {
+	corev1client.ServiceInterface
+	fake      *k8sfake.Clientset
+	namespace string
+}
+
+func (c serviceClient) Apply(ctx context.Context, service *corev1ac.ServiceApplyConfiguration, opts metav1.ApplyOptions) (*corev1.Service, error) {
+	return invokeApply(c.fake, ServicesResource, c.namespace, service.Name, service, opts, &corev1.Service{})
+}
+
+func (c serviceClient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*corev1.Service, error) {
+	return invokePatch(c.fake, ServicesResource, c.namespace, name, pt, data, opts, subresources, &corev1.Service{})
+}
+
+type secretClient struct {
+	corev1client.SecretInterface
+	fake      *k8sfake.Clientset
+	namespace string
+}
+
+func (c secretClient) Apply(ctx context.Context, secret *corev1ac.SecretApplyConfiguration, opts metav1.ApplyOptions) (*corev1.Secret, error) {
+	return invokeApply(c.fake, SecretsResource, c.namespace, secret.Name, secret, opts, &corev1.Secret{})
+}
+
+func (c secretClient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*corev1.Secret, error) {
+	return invokePatch(c.fake, SecretsResource, c.namespace, name, pt, data, opts, subresources, &corev1.Secret{})
+}
+
+// invokeApply records an applyAction of obj on fake and returns the object
+// its reactors return.
+func invokeApply[T runtime.Object](fake *k8sfake.Clientset, resource schema.GroupVersionResource, namespace string, name *string, obj interface{}, opts metav1.ApplyOptions, empty T) (T, error) {
+	if name == nil {
+		return empty, errors.NewBadRequest("name must be provided to Apply")
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return empty, err
+	}
+	action := applyAction{
+		PatchActionImpl: core.NewPatchSubresourceAction(resource, namespace, *name, types.ApplyPatchType, data),
+		options:         opts,
+	}
+	result, err := fake.Invokes(action, empty)
+	if result == nil {
+		return empty, err
+	}
+	return result.(T), err
+}
+
+// invokePatch records a patchAction on fake and returns the object its
+// reactors return.
+func invokePatch[T runtime.Object](fake *k8sfake.Clientset, resource schema.GroupVersionResource, namespace, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources []string, empty T) (T, error) {
+	action := patchAction{
+		PatchActionImpl: core.NewPatchSubresourceAction(resource, namespace, name, pt, data, subresources...),
+		options:         opts,
+	}
+	result, err := fake.Invokes(action, empty)
+	if result == nil {
+		return empty, err
+	}
+	return result.(T), err
+}
@@ -0,0 +1,368 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fixture runs a sync of the Bookstore controller against fake
+// clientsets and informer indexers, and checks the exact API actions and
+// events it produced, in the style of the upstream sample-controller tests.
+package fixture
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+
+	samplev1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+	"k8s.io/sample-controller/pkg/generated/clientset/versioned/fake"
+	informers "k8s.io/sample-controller/pkg/generated/informers/externalversions"
+)
+
+var noResyncPeriodFunc = func() time.Duration { return 0 }
+
+// The resources of the actions a sync may produce.
+var (
+	DeploymentsResource = appsv1.SchemeGroupVersion.WithResource("deployments")
+	ServicesResource    = corev1.SchemeGroupVersion.WithResource("services")
+	SecretsResource     = corev1.SchemeGroupVersion.WithResource("secrets")
+	BookstoresResource  = samplev1alpha1.SchemeGroupVersion.WithResource("bookstores")
+)
+
+// Fixture holds the objects a sync starts from and the actions and events it
+// is expected to produce. Objects are added to the fields below, Init builds
+// the fake clientsets and informers from them, and Run checks a sync.
+type Fixture struct {
+	t *testing.T
+
+	Client     *fake.Clientset
+	KubeClient *k8sfake.Clientset
+
+	InformerFactory     informers.SharedInformerFactory
+	KubeInformerFactory kubeinformers.SharedInformerFactory
+
+	// Recorder records the events of the sync.
+	Recorder *record.FakeRecorder
+
+	// Objects to put in the informer indexers and the fake clientsets.
+	BookstoreLister  []*samplev1alpha1.Bookstore
+	DeploymentLister []*appsv1.Deployment
+	ServiceLister    []*corev1.Service
+	SecretLister     []*corev1.Secret
+	ConfigMapLister  []*corev1.ConfigMap
+
+	// Actions expected to happen on the clients, and events expected to be
+	// recorded, in order.
+	actions     []core.Action
+	kubeactions []core.Action
+	events      []string
+}
+
+// New returns an empty Fixture for t.
+func New(t *testing.T) *Fixture {
+	return &Fixture{t: t}
+}
+
+// Init builds the fake clientsets, seeded with the objects of the fixture,
+// and the informer factories, whose indexers hold the same objects. The
+// informers are never started, so they report as synced and the sync reads
+// exactly the objects of the fixture. Init must be called before the
+// controller under test is built from the fixture; Run calls it if needed.
+func (f *Fixture) Init() {
+	if f.Client != nil {
+		return
+	}
+
+	var objects, kubeobjects []runtime.Object
+	for _, bookstore := range f.BookstoreLister {
+		objects = append(objects, bookstore)
+	}
+	for _, deployment := range f.DeploymentLister {
+		kubeobjects = append(kubeobjects, deployment)
+	}
+	for _, service := range f.ServiceLister {
+		kubeobjects = append(kubeobjects, service)
+	}
+	for _, secret := range f.SecretLister {
+		kubeobjects = append(kubeobjects, secret)
+	}
+	for _, configMap := range f.ConfigMapLister {
+		kubeobjects = append(kubeobjects, configMap)
+	}
+
+	f.Client = fake.NewClientset(objects...)
+	f.KubeClient = k8sfake.NewSimpleClientset(kubeobjects...)
+	f.KubeClient.PrependReactor("patch", "*", fieldManagedReaction(f.KubeClient.Tracker()))
+	f.InformerFactory = informers.NewSharedInformerFactory(f.Client, noResyncPeriodFunc())
+	f.KubeInformerFactory = kubeinformers.NewSharedInformerFactory(f.KubeClient, noResyncPeriodFunc())
+	f.Recorder = record.NewFakeRecorder(100)
+
+	for _, bookstore := range f.BookstoreLister {
+		f.add(f.InformerFactory.Calico().V1alpha1().Bookstores().Informer().GetIndexer().Add(bookstore))
+	}
+	for _, deployment := range f.DeploymentLister {
+		f.add(f.KubeInformerFactory.Apps().V1().Deployments().Informer().GetIndexer().Add(deployment))
+	}
+	for _, service := range f.ServiceLister {
+		f.add(f.KubeInformerFactory.Core().V1().Services().Informer().GetIndexer().Add(service))
+	}
+	for _, secret := range f.SecretLister {
+		f.add(f.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer().Add(secret))
+	}
+	for _, configMap := range f.ConfigMapLister {
+		f.add(f.KubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer().Add(configMap))
+	}
+}
+
+// KubeClientset returns the Kubernetes clientset to build the controller
+// under test with. It is KubeClient, except that its applies are served
+// like the API server serves them, with managedFields and conflicts.
+func (f *Fixture) KubeClientset() kubernetes.Interface {
+	return kubeClient{f.KubeClient}
+}
+
+func (f *Fixture) add(err error) {
+	if err != nil {
+		f.t.Fatalf("error adding object to indexer: %v", err)
+	}
+}
+
+// Run initializes the fixture, calls sync, and checks that it succeeded with
+// the expected actions and events.
+func (f *Fixture) Run(sync func() error) {
+	f.run(sync, false)
+}
+
+// RunExpectError is Run for a sync that is expected to fail. It returns the
+// error of the sync.
+func (f *Fixture) RunExpectError(sync func() error) error {
+	return f.run(sync, true)
+}
+
+func (f *Fixture) run(sync func() error, expectError bool) error {
+	f.Init()
+
+	err := sync()
+	if !expectError && err != nil {
+		f.t.Errorf("error syncing bookstore: %v", err)
+	} else if expectError && err == nil {
+		f.t.Error("expected error syncing bookstore, got nil")
+	}
+
+	checkActions(f.t, "", f.actions, filterInformerActions(f.Client.Actions()))
+	checkActions(f.t, "kube ", f.kubeactions, filterInformerActions(f.KubeClient.Actions()))
+
+	var events []string
+	for len(f.Recorder.Events) > 0 {
+		events = append(events, <-f.Recorder.Events)
+	}
+	if diff := cmp.Diff(f.events, events); diff != "" {
+		f.t.Errorf("unexpected events (-want +got):\n%s", diff)
+	}
+	return err
+}
+
+// checkActions checks that actual holds exactly the expected actions.
+func checkActions(t *testing.T, kind string, expected, actual []core.Action) {
+	for i, action := range actual {
+		if len(expected) < i+1 {
+			t.Errorf("%d unexpected %sactions: %+v", len(actual)-len(expected), kind, actual[i:])
+			break
+		}
+		checkAction(t, expected[i], action)
+	}
+	if len(expected) > len(actual) {
+		t.Errorf("%d additional expected %sactions: %+v", len(expected)-len(actual), kind, expected[len(actual):])
+	}
+}
+
+// checkAction verifies that expected and actual actions are equal and both
+// have the same attached resources.
+func checkAction(t *testing.T, expected, actual core.Action) {
+	switch a := actual.(type) {
+	case applyAction:
+		actual = a.PatchActionImpl
+	case patchAction:
+		actual = a.PatchActionImpl
+	}
+	if !(expected.Matches(actual.GetVerb(), actual.GetResource().Resource) && actual.GetSubresource() == expected.GetSubresource()) {
+		t.Errorf("Expected\n\t%#v\ngot\n\t%#v", expected, actual)
+		return
+	}
+
+	if actual.GetNamespace() != expected.GetNamespace() {
+		t.Errorf("Action %s %s has namespace %q, expected %q", actual.GetVerb(), actual.GetResource().Resource, actual.GetNamespace(), expected.GetNamespace())
+	}
+
+	switch a := actual.(type) {
+	case core.PatchActionImpl:
+		e, _ := expected.(core.PatchActionImpl)
+		checked, isChecked := expected.(checkedApplyAction)
+		if isChecked {
+			e = checked.PatchActionImpl
+		}
+		if a.GetName() != e.GetName() || a.GetPatchType() != e.GetPatchType() {
+			t.Errorf("Action %s %s patches %s with %s, expected %s with %s", a.GetVerb(), a.GetResource().Resource, a.GetName(), a.GetPatchType(), e.GetName(), e.GetPatchType())
+			return
+		}
+		if isChecked {
+			checked.check(a.GetPatch())
+			return
+		}
+		expPatch, actPatch := normalizePatch(t, e.GetPatch()), normalizePatch(t, a.GetPatch())
+		if diff := cmp.Diff(expPatch, actPatch); diff != "" {
+			t.Errorf("Action %s %s has wrong patch (-want +got):\n%s", a.GetVerb(), a.GetResource().Resource, diff)
+		}
+	case core.GetActionImpl:
+		e, _ := expected.(core.GetActionImpl)
+		if a.GetName() != e.GetName() {
+			t.Errorf("Action %s %s gets %s, expected %s", a.GetVerb(), a.GetResource().Resource, a.GetName(), e.GetName())
+		}
+	case core.DeleteActionImpl:
+		e, _ := expected.(core.DeleteActionImpl)
+		if a.GetName() != e.GetName() {
+			t.Errorf("Action %s %s deletes %s, expected %s", a.GetVerb(), a.GetResource().Resource, a.GetName(), e.GetName())
+		}
+	case core.CreateActionImpl:
+		e, _ := expected.(core.CreateActionImpl)
+		if !equality.Semantic.DeepEqual(e.GetObject(), a.GetObject()) {
+			t.Errorf("Action %s %s has wrong object (-want +got):\n%s", a.GetVerb(), a.GetResource().Resource, cmp.Diff(e.GetObject(), a.GetObject()))
+		}
+	case core.UpdateActionImpl:
+		e, _ := expected.(core.UpdateActionImpl)
+		if !equality.Semantic.DeepEqual(e.GetObject(), a.GetObject()) {
+			t.Errorf("Action %s %s has wrong object (-want +got):\n%s", a.GetVerb(), a.GetResource().Resource, cmp.Diff(e.GetObject(), a.GetObject()))
+		}
+	default:
+		t.Errorf("Uncaptured Action %s %s, you should explicitly add a case to capture it",
+			actual.GetVerb(), actual.GetResource().Resource)
+	}
+}
+
+// normalizePatch decodes a JSON patch so that patches can be compared
+// regardless of field order. Condition transition times are read from the
+// clock, so they are left out of the comparison.
+func normalizePatch(t *testing.T, patch []byte) interface{} {
+	var decoded interface{}
+	if err := json.Unmarshal(patch, &decoded); err != nil {
+		t.Errorf("error decoding patch %s: %v", patch, err)
+		return nil
+	}
+	dropTransitionTimes(decoded)
+	return decoded
+}
+
+func dropTransitionTimes(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		delete(v, "lastTransitionTime")
+		for _, field := range v {
+			dropTransitionTimes(field)
+		}
+	case []interface{}:
+		for _, item := range v {
+			dropTransitionTimes(item)
+		}
+	}
+}
+
+// filterInformerActions filters list and watch actions for testing
+// resources. Since list and watch don't change resource state we can filter
+// it to lower nose level in our tests.
+func filterInformerActions(actions []core.Action) []core.Action {
+	ret := []core.Action{}
+	for _, action := range actions {
+		if action.GetVerb() == "list" || action.GetVerb() == "watch" {
+			continue
+		}
+		ret = append(ret, action)
+	}
+	return ret
+}
+
+// ExpectApply expects a server-side apply of the Bookstore client with the
+// apply configuration obj. A subresource such as "status" may be given.
+func (f *Fixture) ExpectApply(resource schema.GroupVersionResource, namespace, name string, obj interface{}, subresources ...string) {
+	f.actions = append(f.actions, f.applyAction(resource, namespace, name, obj, subresources...))
+}
+
+// ExpectKubeApply is ExpectApply for the Kubernetes client.
+func (f *Fixture) ExpectKubeApply(resource schema.GroupVersionResource, namespace, name string, obj interface{}, subresources ...string) {
+	f.kubeactions = append(f.kubeactions, f.applyAction(resource, namespace, name, obj, subresources...))
+}
+
+func (f *Fixture) applyAction(resource schema.GroupVersionResource, namespace, name string, obj interface{}, subresources ...string) core.Action {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		f.t.Fatalf("error encoding apply configuration: %v", err)
+	}
+	return core.NewPatchSubresourceAction(resource, namespace, name, types.ApplyPatchType, data, subresources...)
+}
+
+// ExpectKubeApplyFunc is ExpectKubeApply for an apply whose configuration
+// cannot be known in advance, such as random credentials. check is called
+// with the applied configuration once the sync is done, and reports what is
+// wrong with it through the test.
+func (f *Fixture) ExpectKubeApplyFunc(resource schema.GroupVersionResource, namespace, name string, check func(applied []byte)) {
+	f.kubeactions = append(f.kubeactions, checkedApplyAction{
+		PatchActionImpl: core.NewPatchSubresourceAction(resource, namespace, name, types.ApplyPatchType, nil),
+		check:           check,
+	})
+}
+
+// checkedApplyAction is an expected apply checked by a function.
+type checkedApplyAction struct {
+	core.PatchActionImpl
+	check func(applied []byte)
+}
+
+// ExpectPatch expects a patch of the Bookstore client.
+func (f *Fixture) ExpectPatch(resource schema.GroupVersionResource, namespace, name string, patchType types.PatchType, patch []byte) {
+	f.actions = append(f.actions, core.NewPatchAction(resource, namespace, name, patchType, patch))
+}
+
+// ExpectKubePatch is ExpectPatch for the Kubernetes client.
+func (f *Fixture) ExpectKubePatch(resource schema.GroupVersionResource, namespace, name string, patchType types.PatchType, patch []byte) {
+	f.kubeactions = append(f.kubeactions, core.NewPatchAction(resource, namespace, name, patchType, patch))
+}
+
+// ExpectKubeGet expects a get of the Kubernetes client, which bypasses the
+// informer cache.
+func (f *Fixture) ExpectKubeGet(resource schema.GroupVersionResource, namespace, name string) {
+	f.kubeactions = append(f.kubeactions, core.NewGetAction(resource, namespace, name))
+}
+
+// ExpectKubeDelete expects a delete of the Kubernetes client.
+func (f *Fixture) ExpectKubeDelete(resource schema.GroupVersionResource, namespace, name string) {
+	f.kubeactions = append(f.kubeactions, core.NewDeleteAction(resource, namespace, name))
+}
+
+// ExpectEvent expects an event in the format of record.FakeRecorder, e.g.
+// "Normal Synced Bookstore synced successfully".
+func (f *Fixture) ExpectEvent(eventType, reason, message string) {
+	f.events = append(f.events, eventType+" "+reason+" "+message)
+}
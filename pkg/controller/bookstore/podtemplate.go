@@ -0,0 +1,64 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bookstore
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	samplev1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+	"k8s.io/sample-controller/pkg/apis/calico/validation"
+)
+
+// withPodTemplate strategic-merge-patches the podTemplate overlay of the
+// Bookstore on top of the pod template of deployment, as built by
+// newDeployment. The overlay is validated first, because the validating
+// webhook is optional, so that it never changes the selector labels or
+// drops the bookstore container.
+func withPodTemplate(deployment *appsv1ac.DeploymentApplyConfiguration, bookstore *samplev1alpha1.Bookstore) (*appsv1ac.DeploymentApplyConfiguration, error) {
+	overlay := bookstore.Spec.PodTemplate
+	if overlay == nil || len(overlay.Raw) == 0 {
+		return deployment, nil
+	}
+	if errs := validation.ValidatePodTemplate(bookstore, field.NewPath("spec", "podTemplate")); len(errs) > 0 {
+		return nil, &invalidSpecError{message: errs.ToAggregate().Error()}
+	}
+
+	// The apply configuration serializes to the fields set on it only, so
+	// the patched template does not gain zero values the controller would
+	// then own.
+	original, err := json.Marshal(deployment.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := strategicpatch.StrategicMergePatch(original, overlay.Raw, corev1.PodTemplateSpec{})
+	if err != nil {
+		return nil, &invalidSpecError{message: fmt.Sprintf("spec.podTemplate: %v", err)}
+	}
+	template := &corev1ac.PodTemplateSpecApplyConfiguration{}
+	if err := json.Unmarshal(patched, template); err != nil {
+		return nil, &invalidSpecError{message: fmt.Sprintf("spec.podTemplate: %v", err)}
+	}
+	deployment.Spec.WithTemplate(template)
+	return deployment, nil
+}
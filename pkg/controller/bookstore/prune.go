@@ -0,0 +1,102 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bookstore
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	samplev1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+)
+
+// ManagedByLabel is set to FieldManager on every Deployment and
+// Service the controller creates, so that it can list them cheaply.
+const ManagedByLabel = "app.kubernetes.io/managed-by"
+
+// managedLabels returns labels with ManagedByLabel added.
+func managedLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[ManagedByLabel] = FieldManager
+	return labels
+}
+
+// pruneReplacedChildren deletes the Deployments and Services controlled by
+// the Bookstore that are no longer named in its spec, which happens when
+// deploymentName or serviceName is renamed. deployment is the current
+// Deployment of the Bookstore; nothing is deleted until its rollout is
+// complete, so the old children keep serving until the new ones can.
+func (r *reconciler) pruneReplacedChildren(ctx context.Context, bookstore *samplev1alpha1.Bookstore, deployment *appsv1.Deployment) error {
+	logger := klog.LoggerWithValues(klog.FromContext(ctx), "bookstore", klog.KObj(bookstore))
+	if complete, _, _ := deploymentRolloutStatus(deployment); !complete {
+		return nil
+	}
+	selector := labels.SelectorFromSet(labels.Set{ManagedByLabel: FieldManager})
+
+	deployments, err := r.deploymentsLister.Deployments(bookstore.Namespace).List(selector)
+	if err != nil {
+		return err
+	}
+	for _, old := range deployments {
+		if old.Name == bookstore.Spec.DeploymentName || old.DeletionTimestamp != nil || !metav1.IsControlledBy(old, bookstore) {
+			continue
+		}
+		logger.V(4).Info("Delete replaced deployment", "deployment", klog.KObj(old))
+		err := r.kubeclientset.AppsV1().Deployments(old.Namespace).Delete(context.TODO(), old.Name, preconditionsFor(old))
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		r.recorder.Eventf(bookstore, corev1.EventTypeNormal, Pruned, MessagePruned, "Deployment", old.Name, bookstore.Spec.DeploymentName)
+	}
+
+	services, err := r.serviceLister.Services(bookstore.Namespace).List(selector)
+	if err != nil {
+		return err
+	}
+	for _, old := range services {
+		if old.Name == bookstore.Spec.ServiceName || old.DeletionTimestamp != nil || !metav1.IsControlledBy(old, bookstore) {
+			continue
+		}
+		logger.V(4).Info("Delete replaced service", "service", klog.KObj(old))
+		err := r.kubeclientset.CoreV1().Services(old.Namespace).Delete(context.TODO(), old.Name, preconditionsFor(old))
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		r.recorder.Eventf(bookstore, corev1.EventTypeNormal, Pruned, MessagePruned, "Service", old.Name, bookstore.Spec.ServiceName)
+	}
+	return nil
+}
+
+// preconditionsFor returns delete options that only delete obj if it was
+// not changed or replaced since it was read.
+func preconditionsFor(obj metav1.Object) metav1.DeleteOptions {
+	uid := obj.GetUID()
+	resourceVersion := obj.GetResourceVersion()
+	return metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{
+			UID:             &uid,
+			ResourceVersion: &resourceVersion,
+		},
+	}
+}
@@ -0,0 +1,628 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bookstore implements the reconciliation of Bookstore resources,
+// separate from the workqueue and workers that drive it.
+package bookstore
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+	appsinformers "k8s.io/client-go/informers/apps/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+
+	samplev1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+	samplev1alpha1ac "k8s.io/sample-controller/pkg/generated/applyconfiguration/calico/v1alpha1"
+	clientset "k8s.io/sample-controller/pkg/generated/clientset/versioned"
+	informers "k8s.io/sample-controller/pkg/generated/informers/externalversions/calico/v1alpha1"
+	listers "k8s.io/sample-controller/pkg/generated/listers/calico/v1alpha1"
+	"k8s.io/sample-controller/pkg/metrics"
+)
+
+// FieldManager is the field manager of every server-side apply of the
+// reconciler, and the value of ManagedByLabel on the children it creates.
+const FieldManager = "sample-controller"
+
+const (
+	// SuccessSynced is used as part of the Event 'reason' when a Bookstore is synced
+	SuccessSynced = "Synced"
+	// ErrResourceExists is used as part of the Event 'reason' when a Bookstore fails
+	// to sync due to a Deployment of the same name already existing.
+	ErrResourceExists = "ErrResourceExists"
+
+	// MessageResourceExists is the message used for Events when a resource
+	// fails to sync due to a Deployment already existing
+	MessageResourceExists = "Resource %q already exists and is not managed by Bookstore"
+	// FieldConflict is used as part of the Event 'reason' when a child of a
+	// Bookstore cannot be applied because another field manager set some of
+	// the fields it applies to different values.
+	FieldConflict = "FieldConflict"
+	// MessageFieldConflict is the message used for Events when a child fails
+	// to sync due to fields managed by someone else
+	MessageFieldConflict = "%s %q has fields managed by someone else: %s"
+	// MessageResourceSynced is the message used for an Event fired when a Bookstore
+	// is synced successfully
+	MessageResourceSynced = "Bookstore synced successfully"
+
+	// ServiceRecreated is used as part of the Event 'reason' when a Service
+	// had to be deleted and created again to apply an immutable change.
+	ServiceRecreated = "ServiceRecreated"
+	// MessageServiceRecreated is the message used for an Event fired when a
+	// Service is deleted to be recreated
+	MessageServiceRecreated = "Service %q deleted to be recreated: %s"
+
+	// SecretMissing is used as part of the Event 'reason' when a Bookstore
+	// is not rolled out because its credentials Secret or one of the keys
+	// it reads is missing.
+	SecretMissing = "SecretMissing"
+
+	// Adopted is used as part of the Event 'reason' when a Bookstore adopts
+	// an existing Deployment or Service.
+	Adopted = "Adopted"
+	// MessageAdopted is the message used for an Event fired when a
+	// Deployment or Service is adopted
+	MessageAdopted = "%s %q adopted"
+
+	// Pruned is used as part of the Event 'reason' when a Deployment or
+	// Service is deleted after it was replaced by a renamed one.
+	Pruned = "Pruned"
+	// MessagePruned is the message used for an Event fired when a replaced
+	// Deployment or Service is deleted
+	MessagePruned = "%s %q deleted, replaced by %q"
+
+	// SecretGenerated is used as part of the Event 'reason' when the
+	// credentials Secret of a Bookstore is generated by the controller.
+	SecretGenerated = "SecretGenerated"
+	// MessageSecretGenerated is the message used for an Event fired when a
+	// credentials Secret is generated
+	MessageSecretGenerated = "Secret %q generated with random credentials"
+	// SecretRegenerated is used as part of the Event 'reason' when new
+	// credentials are generated on request.
+	SecretRegenerated = "SecretRegenerated"
+	// MessageSecretRegenerated is the message used for an Event fired when
+	// new credentials are generated
+	MessageSecretRegenerated = "Secret %q regenerated with new random credentials"
+)
+
+// Result is the outcome of a Reconcile that did not fail.
+type Result struct {
+	// RequeueAfter, if positive, asks for the Bookstore to be reconciled
+	// again after this delay, even if none of the objects it depends on
+	// change in the meantime. It is meant for time-based follow-ups, such
+	// as polling the progress of a rollout.
+	RequeueAfter time.Duration
+}
+
+// Reconciler converges the children and the status of a Bookstore with its
+// spec. It knows nothing about the queue that calls it: the caller decides
+// how to requeue the Bookstore from the Result, or from the ErrorClass of
+// the error if Reconcile fails.
+type Reconciler interface {
+	// Reconcile reconciles the Bookstore with the given namespace/name key.
+	Reconcile(ctx context.Context, key string) (Result, error)
+}
+
+// reconciler is the Reconciler of Bookstores.
+type reconciler struct {
+	// kubeclientset is a standard kubernetes clientset
+	kubeclientset kubernetes.Interface
+	// sampleclientset is a clientset for our own API group
+	sampleclientset clientset.Interface
+
+	serviceLister     corelisters.ServiceLister
+	secretsLister     corelisters.SecretLister
+	configMapsLister  corelisters.ConfigMapLister
+	deploymentsLister appslisters.DeploymentLister
+	bookstoresLister  listers.BookstoreLister
+
+	// recorder is an event recorder for recording Event resources to the
+	// Kubernetes API.
+	recorder record.EventRecorder
+
+	// sleepAction tells whether the preStop hooks of Deployments may use the
+	// sleep action, which needs Kubernetes 1.30 or later.
+	sleepAction bool
+}
+
+// NewReconciler returns a Reconciler that reads Bookstores and their
+// children from the listers of the given informers, and writes them with
+// the given clientsets. sleepAction tells whether the API server accepts the
+// sleep action in lifecycle hooks, as reported by SupportsSleepAction.
+func NewReconciler(
+	kubeclientset kubernetes.Interface,
+	sampleclientset clientset.Interface,
+	deploymentInformer appsinformers.DeploymentInformer,
+	serviceInformer coreinformers.ServiceInformer,
+	secretInformer coreinformers.SecretInformer,
+	configMapInformer coreinformers.ConfigMapInformer,
+	bookstoreInformer informers.BookstoreInformer,
+	recorder record.EventRecorder,
+	sleepAction bool) Reconciler {
+	return &reconciler{
+		kubeclientset:     kubeclientset,
+		sampleclientset:   sampleclientset,
+		serviceLister:     serviceInformer.Lister(),
+		secretsLister:     secretInformer.Lister(),
+		configMapsLister:  configMapInformer.Lister(),
+		deploymentsLister: deploymentInformer.Lister(),
+		bookstoresLister:  bookstoreInformer.Lister(),
+		recorder:          recorder,
+		sleepAction:       sleepAction,
+	}
+}
+
+// Reconcile compares the actual state with the desired, and attempts to
+// converge the two. It then updates the Status block of the Bookstore resource
+// with the current status of the resource.
+func (r *reconciler) Reconcile(ctx context.Context, key string) (_ Result, err error) {
+	skipped := false
+	defer func(start time.Time) {
+		if !skipped {
+			metrics.ObserveSync(syncResult(err), time.Since(start))
+		}
+	}(time.Now())
+
+	// Convert the namespace/name string into a distinct namespace and name
+	logger := klog.LoggerWithValues(klog.FromContext(ctx), "resourceName", key)
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		logger.Error(err, "invalid resource key")
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return Result{}, nil
+	}
+
+	// Get the Booksore resource with this namespace/name
+	bookstore, err := r.bookstoresLister.Bookstores(namespace).Get(name)
+	if err != nil {
+		// The Bookstore resource may no longer exist, in which case we stop
+		// processing.
+		if errors.IsNotFound(err) {
+			utilruntime.HandleError(fmt.Errorf("bookstore '%s' in work queue no longer exists", key))
+			return Result{}, nil
+		}
+		logger.Error(err, "bookstore lister unknown error")
+		return Result{}, err
+	}
+
+	// Fill in the fields the defaulting webhook would have derived, in case
+	// the Bookstore was admitted without it.
+	bookstore = bookstore.DeepCopy()
+	samplev1alpha1.SetObjectDefaults_Bookstore(bookstore)
+
+	// A Bookstore being deleted is not synced any more, only its deletion
+	// policy is enforced.
+	if bookstore.DeletionTimestamp != nil {
+		return Result{}, r.finalizeBookstore(ctx, bookstore)
+	}
+
+	// A Bookstore that failed terminally is not synced again until its spec
+	// changes, or, if a child belonged to someone else, until that child is
+	// gone or may be adopted.
+	if failed := meta.FindStatusCondition(bookstore.Status.Conditions, samplev1alpha1.BookstoreFailed); failed != nil && failed.Status == metav1.ConditionTrue && failed.ObservedGeneration == bookstore.Generation {
+		if failed.Reason != ErrResourceExists || r.childConflicts(bookstore) {
+			logger.V(4).Info("Skip failed bookstore until its spec changes", "reason", failed.Reason)
+			skipped = true
+			return Result{}, nil
+		}
+		logger.V(4).Info("Sync failed bookstore whose conflicting child was resolved")
+	}
+	if err := r.ensureFinalizer(bookstore); err != nil {
+		return Result{}, err
+	}
+
+	deployment, err := r.syncBookstore(ctx, bookstore)
+
+	// Whatever the outcome, we update the status block of the Bookstore
+	// resource to reflect the current state of the world
+	statusErr := r.updateBookstoreStatus(bookstore, deployment, err)
+	if statusErr != nil {
+		logger.Error(statusErr, "error updating bookstore status")
+	}
+
+	if err != nil {
+		// The Failed condition of a terminal error must be recorded before
+		// the Bookstore stops being retried.
+		if statusErr != nil && isTerminal(err) {
+			return Result{}, statusErr
+		}
+		return Result{}, err
+	}
+	if statusErr != nil {
+		return Result{}, statusErr
+	}
+
+	r.recorder.Event(bookstore, corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+	return Result{}, nil
+}
+
+// syncBookstore creates or patches the Deployment and Service of a Bookstore
+// so that they match its spec. It returns the Deployment it observed, which
+// is nil if the sync failed before the Deployment could be read or created.
+func (r *reconciler) syncBookstore(ctx context.Context, bookstore *samplev1alpha1.Bookstore) (*appsv1.Deployment, error) {
+	logger := klog.LoggerWithValues(klog.FromContext(ctx), "bookstore", klog.KObj(bookstore))
+
+	// Pods started without their credentials would sit in
+	// CreateContainerConfigError, so nothing is rolled out until the Secret
+	// and every key read from it exist.
+	var secret *corev1.Secret
+	var err error
+	if bookstore.Spec.GenerateSecret {
+		secret, err = r.syncGeneratedSecret(ctx, bookstore)
+	} else {
+		secret, err = r.credentialsSecret(bookstore)
+	}
+	if err != nil {
+		if isSecretMissing(err) {
+			r.recorder.Event(bookstore, corev1.EventTypeWarning, SecretMissing, err.Error())
+		}
+		return nil, err
+	}
+	configMap, err := r.referencedConfigMap(bookstore)
+	if err != nil {
+		return nil, err
+	}
+	configHash := podConfigHash(bookstore, secret, configMap)
+
+	if bookstore.Spec.DeploymentName == "" {
+		return nil, &invalidSpecError{message: "deployment name must be specified"}
+	}
+
+	// Get the deployment with the name specified in Bookstore.spec
+	deployment, err := r.deploymentsLister.Deployments(bookstore.Namespace).Get(bookstore.Spec.DeploymentName)
+	if errors.IsNotFound(err) {
+		deployment, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// If the Deployment is not controlled by this Bookstore resource, we adopt
+	// it if the Bookstore asked for it. Otherwise we log a warning to the
+	// event recorder and return error msg.
+	if deployment != nil && !metav1.IsControlledBy(deployment, bookstore) {
+		patch, err := r.adoptionPatch(bookstore, deployment, deploymentUnadoptable(bookstore, deployment))
+		if err != nil {
+			return nil, err
+		}
+		logger.V(4).Info("Adopt deployment resource", "deployment", klog.KObj(deployment))
+		deployment, err = r.kubeclientset.AppsV1().Deployments(bookstore.Namespace).Patch(context.TODO(), deployment.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			logger.Error(err, "error adopting deployment")
+			return nil, err
+		}
+		r.recorder.Eventf(bookstore, corev1.EventTypeNormal, Adopted, MessageAdopted, "Deployment", deployment.Name)
+	}
+
+	// Apply the Deployment we want if it does not exist yet, or if any field
+	// we applied has drifted, either because the Bookstore changed or because
+	// the Deployment was edited by hand.
+	desiredDeployment, err := withPodTemplate(newDeployment(bookstore, configHash, r.sleepAction), bookstore)
+	if err != nil {
+		return nil, err
+	}
+	if deployment != nil {
+		desiredDeployment.Spec.WithSelector(labelSelector(deployment.Spec.Selector))
+	}
+	drifted, err := deploymentDrifted(desiredDeployment, deployment)
+	if err == nil && drifted {
+		logger.V(4).Info("Apply deployment resource", "deployment", klog.KRef(bookstore.Namespace, bookstore.Spec.DeploymentName))
+		deployment, err = r.applyDeployment(bookstore, desiredDeployment, deployment)
+	}
+
+	// If an error occurs during Apply, we'll requeue the item so we can
+	// attempt processing again later. This could have been caused by a
+	// temporary network failure, or any other transient reason.
+	if err != nil {
+		logger.Error(err, "error applying deployment")
+		return nil, err
+	}
+
+	if bookstore.Spec.ServiceName == "" {
+		return deployment, &invalidSpecError{message: "service name must be specified"}
+	}
+
+	service, err := r.serviceLister.Services(bookstore.Namespace).Get(bookstore.Spec.ServiceName)
+	if errors.IsNotFound(err) {
+		service, err = nil, nil
+	}
+	if err != nil {
+		return deployment, err
+	}
+
+	if service != nil && !metav1.IsControlledBy(service, bookstore) {
+		patch, err := r.adoptionPatch(bookstore, service, "")
+		if err != nil {
+			return deployment, err
+		}
+		logger.V(4).Info("Adopt service resource", "service", klog.KObj(service))
+		service, err = r.kubeclientset.CoreV1().Services(bookstore.Namespace).Patch(context.TODO(), service.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			logger.Error(err, "error adopting service")
+			return deployment, err
+		}
+		r.recorder.Eventf(bookstore, corev1.EventTypeNormal, Adopted, MessageAdopted, "Service", service.Name)
+	}
+
+	// A Service we deleted to recreate may linger while its finalizers run.
+	// Wait for it to go away instead of applying to a terminating object.
+	if service != nil && service.DeletionTimestamp != nil {
+		return deployment, fmt.Errorf("service %q is being deleted", service.Name)
+	}
+
+	// Bring the Service in line with the Bookstore spec. Ports and type are
+	// applied in place, but changes the API server refuses to apply to a
+	// live Service force us to delete and recreate it. The new Service is
+	// only applied by a later sync, once the old one is gone: its deletion
+	// requeues the Bookstore.
+	desiredService := newService(bookstore)
+	if service != nil {
+		if reason := serviceRecreateReason(corev1.ServiceType(bookstore.Spec.ServiceType), service); reason != "" {
+			logger.V(4).Info("Recreate service resource", "service", klog.KObj(service), "reason", reason)
+			if err = r.deleteService(service); err != nil {
+				logger.Error(err, "error deleting service to recreate it")
+				return deployment, err
+			}
+			r.recorder.Event(bookstore, corev1.EventTypeNormal, ServiceRecreated, fmt.Sprintf(MessageServiceRecreated, service.Name, reason))
+			return deployment, fmt.Errorf("service %q is being recreated", service.Name)
+		}
+	}
+	drifted, err = serviceDrifted(desiredService, service)
+	if err == nil && drifted {
+		logger.V(4).Info("Apply service resource", "service", klog.KRef(bookstore.Namespace, bookstore.Spec.ServiceName))
+		_, err = r.applyService(bookstore, desiredService, service)
+	}
+
+	if err != nil {
+		logger.Error(err, "error applying service")
+		return deployment, err
+	}
+
+	// Now that the children named in the spec are in place, remove the ones
+	// they replaced after a rename.
+	if err = r.pruneReplacedChildren(ctx, bookstore, deployment); err != nil {
+		logger.Error(err, "error deleting replaced children")
+		return deployment, err
+	}
+
+	return deployment, nil
+}
+
+// credentialsSecret returns the credentials Secret of the Bookstore. It
+// returns a secretMissingError if the Secret does not exist or lacks any of
+// the keys that newDeployment exposes to the bookstore container.
+func (r *reconciler) credentialsSecret(bookstore *samplev1alpha1.Bookstore) (*corev1.Secret, error) {
+	name := bookstore.GetCredentialsSecretName()
+	secret, err := r.secretsLister.Secrets(bookstore.Namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil, &secretMissingError{name: name}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var missingKeys []string
+	for _, key := range []string{bookstore.Spec.EnvAdminUsername, bookstore.Spec.EnvAdminPassword, bookstore.Spec.EnvJWTSECRET} {
+		if _, ok := secret.Data[key]; !ok && !slices.Contains(missingKeys, key) {
+			missingKeys = append(missingKeys, key)
+		}
+	}
+	if len(missingKeys) > 0 {
+		return nil, &secretMissingError{name: name, keys: missingKeys}
+	}
+	return secret, nil
+}
+
+// referencedConfigMap returns the ConfigMap referenced by the Bookstore, or
+// nil if it references none or the ConfigMap does not exist. The ConfigMap is
+// optional for the bookstore container, so a missing one is not an error.
+func (r *reconciler) referencedConfigMap(bookstore *samplev1alpha1.Bookstore) (*corev1.ConfigMap, error) {
+	if bookstore.Spec.ConfigMapRef == nil {
+		return nil, nil
+	}
+	configMap, err := r.configMapsLister.ConfigMaps(bookstore.Namespace).Get(bookstore.Spec.ConfigMapRef.Name)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	return configMap, err
+}
+
+// updateBookstoreStatus records the outcome of a sync in the status block of
+// the Bookstore resource. deployment and syncErr are the results returned by
+// syncBookstore.
+func (r *reconciler) updateBookstoreStatus(bookstore *samplev1alpha1.Bookstore, deployment *appsv1.Deployment, syncErr error) error {
+	// NEVER modify objects from the store. It's a read-only, local cache.
+	// You can use DeepCopy() to make a deep copy of original object and modify this copy
+	// Or create a copy manually for better performance
+	bookstoreCopy := bookstore.DeepCopy()
+	bookstoreCopy.Status.ObservedGeneration = bookstore.Generation
+	// The selector is published for the scale subresource, so that a
+	// HorizontalPodAutoscaler can find the pods of the Bookstore.
+	bookstoreCopy.Status.Selector = labels.SelectorFromSet(bookstore.GetSelectorLabels()).String()
+	bookstoreCopy.Status.SecretName = bookstore.GetCredentialsSecretName()
+	if deployment != nil {
+		bookstoreCopy.Status.Replicas = deployment.Status.Replicas
+		bookstoreCopy.Status.AvailableReplicas = deployment.Status.AvailableReplicas
+	}
+	setStatusConditions(&bookstoreCopy.Status, bookstore.Generation, deployment, syncErr)
+
+	// Skip the write if nothing changed, so that a status update does not
+	// trigger another sync of the same Bookstore for no reason.
+	if equality.Semantic.DeepEqual(bookstore.Status, bookstoreCopy.Status) {
+		return nil
+	}
+
+	// The status is applied through the status subresource, which will not
+	// allow changes to the Spec of the resource. This is ideal for ensuring
+	// nothing other than resource status has been updated.
+	_, err := r.sampleclientset.CalicoV1alpha1().Bookstores(bookstore.Namespace).ApplyStatus(context.TODO(), bookstoreStatus(bookstore, &bookstoreCopy.Status), forceApplyOptions)
+	return err
+}
+
+// bookstoreStatus returns the apply configuration that sets the status of
+// the Bookstore to status.
+func bookstoreStatus(bookstore *samplev1alpha1.Bookstore, status *samplev1alpha1.BookstoreStatus) *samplev1alpha1ac.BookstoreApplyConfiguration {
+	applied := samplev1alpha1ac.BookstoreStatus().
+		WithObservedGeneration(status.ObservedGeneration).
+		WithReplicas(status.Replicas).
+		WithAvailableReplicas(status.AvailableReplicas).
+		WithSelector(status.Selector).
+		WithSecretName(status.SecretName)
+	for _, condition := range status.Conditions {
+		applied.WithConditions(metav1ac.Condition().
+			WithType(condition.Type).
+			WithStatus(condition.Status).
+			WithObservedGeneration(condition.ObservedGeneration).
+			WithLastTransitionTime(condition.LastTransitionTime).
+			WithReason(condition.Reason).
+			WithMessage(condition.Message))
+	}
+	return samplev1alpha1ac.Bookstore(bookstore.Name, bookstore.Namespace).WithStatus(applied)
+}
+
+// newDeployment returns the apply configuration of the Deployment for a
+// Bookstore resource. It also sets the appropriate OwnerReferences on the
+// resource so the controller can discover the Bookstore resource that 'owns'
+// it. configHash is the podConfigHash of the data the pods read, recorded on
+// the pod template, and sleepAction is passed on to withWorkload. Only the
+// fields set here are owned by the controller.
+func newDeployment(bookstore *samplev1alpha1.Bookstore, configHash string, sleepAction bool) *appsv1ac.DeploymentApplyConfiguration {
+	container := corev1ac.Container().
+		WithName(bookstore.Spec.DeploymentName).
+		WithImage(bookstore.Spec.DeploymentImageName+":"+bookstore.Spec.DeploymentImageTag).
+		WithImagePullPolicy(corev1.PullPolicy(bookstore.Spec.ImagePullPolicy)).
+		WithPorts(corev1ac.ContainerPort().
+			WithContainerPort(bookstore.Spec.ContainerPort).
+			WithProtocol(corev1.ProtocolTCP)).
+		WithEnv(
+			secretEnvVar("AdminUsername", bookstore.GetCredentialsSecretName(), bookstore.Spec.EnvAdminUsername),
+			secretEnvVar("AdminPassword", bookstore.GetCredentialsSecretName(), bookstore.Spec.EnvAdminPassword),
+			secretEnvVar("JWTSECRET", bookstore.GetCredentialsSecretName(), bookstore.Spec.EnvJWTSECRET),
+		)
+	if bookstore.Spec.ConfigMapRef != nil {
+		container.WithEnvFrom(corev1ac.EnvFromSource().
+			WithConfigMapRef(corev1ac.ConfigMapEnvSource().
+				WithName(bookstore.Spec.ConfigMapRef.Name).
+				WithOptional(true)))
+	}
+	withWorkload(container, bookstore, sleepAction)
+
+	podSpec := corev1ac.PodSpec().
+		WithContainers(container)
+	if period := bookstore.Spec.Workload.TerminationGracePeriodSeconds; period != nil {
+		podSpec.WithTerminationGracePeriodSeconds(*period)
+	}
+
+	spec := appsv1ac.DeploymentSpec().
+		WithSelector(metav1ac.LabelSelector().
+			WithMatchLabels(bookstore.GetSelectorLabels())).
+		WithTemplate(corev1ac.PodTemplateSpec().
+			WithLabels(bookstore.GetSelectorLabels()).
+			WithAnnotations(map[string]string{
+				ConfigHashAnnotation: configHash,
+			}).
+			WithSpec(podSpec))
+	// A Bookstore without replicas leaves the size of the Deployment to
+	// whoever else sets it, such as a HorizontalPodAutoscaler.
+	if bookstore.Spec.Replicas != nil {
+		spec.WithReplicas(*bookstore.Spec.Replicas)
+	}
+
+	return appsv1ac.Deployment(bookstore.Spec.DeploymentName, bookstore.Namespace).
+		WithLabels(managedLabels(nil)).
+		WithOwnerReferences(controllerRef(bookstore)).
+		WithSpec(spec)
+}
+
+// secretEnvVar returns an environment variable set from a required key of
+// a Secret.
+func secretEnvVar(name, secretName, key string) *corev1ac.EnvVarApplyConfiguration {
+	return corev1ac.EnvVar().
+		WithName(name).
+		WithValueFrom(corev1ac.EnvVarSource().
+			WithSecretKeyRef(corev1ac.SecretKeySelector().
+				WithName(secretName).
+				WithKey(key).
+				WithOptional(false)))
+}
+
+// controllerRef returns the apply configuration of the OwnerReference that
+// makes the Bookstore the controller of a child.
+func controllerRef(bookstore *samplev1alpha1.Bookstore) *metav1ac.OwnerReferenceApplyConfiguration {
+	ref := metav1.NewControllerRef(bookstore, samplev1alpha1.SchemeGroupVersion.WithKind("Bookstore"))
+	return metav1ac.OwnerReference().
+		WithAPIVersion(ref.APIVersion).
+		WithKind(ref.Kind).
+		WithName(ref.Name).
+		WithUID(ref.UID).
+		WithController(*ref.Controller).
+		WithBlockOwnerDeletion(*ref.BlockOwnerDeletion)
+}
+
+// deleteService deletes the live Service so that it can be recreated. The
+// delete is guarded by UID and resourceVersion preconditions so that a
+// Service which changed after it was read is never removed by mistake.
+func (r *reconciler) deleteService(live *corev1.Service) error {
+	err := r.kubeclientset.CoreV1().Services(live.Namespace).Delete(context.TODO(), live.Name, preconditionsFor(live))
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// newService returns the apply configuration of the Service for a Bookstore
+// resource, owned by the Bookstore in the same way as the Deployment returned
+// by newDeployment. A node port is only applied if the spec sets one, so that
+// a port allocated by the API server is kept.
+func newService(bookstore *samplev1alpha1.Bookstore) *corev1ac.ServiceApplyConfiguration {
+	port := corev1ac.ServicePort().
+		WithProtocol(corev1.ProtocolTCP).
+		WithPort(bookstore.Spec.ContainerPort).
+		WithTargetPort(intstr.FromInt32(bookstore.Spec.TargetPort))
+	if bookstore.Spec.NodePort != 0 {
+		port.WithNodePort(bookstore.Spec.NodePort)
+	}
+
+	return corev1ac.Service(bookstore.Spec.ServiceName, bookstore.Namespace).
+		WithLabels(managedLabels(bookstore.GetSelectorLabels())).
+		WithOwnerReferences(controllerRef(bookstore)).
+		WithSpec(corev1ac.ServiceSpec().
+			WithSelector(bookstore.GetSelectorLabels()).
+			WithType(corev1.ServiceType(bookstore.Spec.ServiceType)).
+			WithPorts(port))
+}
@@ -0,0 +1,1338 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bookstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/version"
+	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+
+	samplev1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+	"k8s.io/sample-controller/pkg/controller/bookstore/fixture"
+	samplev1alpha1ac "k8s.io/sample-controller/pkg/generated/applyconfiguration/calico/v1alpha1"
+)
+
+func newBookstore(name string, replicas *int32) *samplev1alpha1.Bookstore {
+	bookstore := &samplev1alpha1.Bookstore{
+		TypeMeta: metav1.TypeMeta{APIVersion: samplev1alpha1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  metav1.NamespaceDefault,
+			UID:        types.UID(name + "-uid"),
+			Generation: 1,
+			Finalizers: []string{BookstoreFinalizer},
+		},
+		Spec: samplev1alpha1.BookstoreSpec{
+			EnvAdminUsername:    "ADMIN_USERNAME",
+			EnvAdminPassword:    "ADMIN_PASSWORD",
+			EnvJWTSECRET:        "JWT_SECRET",
+			DeploymentImageName: "bookstore",
+			DeploymentImageTag:  "latest",
+			Replicas:            replicas,
+			ContainerPort:       3000,
+		},
+	}
+	samplev1alpha1.SetObjectDefaults_Bookstore(bookstore)
+	return bookstore
+}
+
+func newSecret(bookstore *samplev1alpha1.Bookstore) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bookstore.GetCredentialsSecretName(),
+			Namespace: bookstore.Namespace,
+		},
+		Data: map[string][]byte{
+			bookstore.Spec.EnvAdminUsername: []byte("admin"),
+			bookstore.Spec.EnvAdminPassword: []byte("password"),
+			bookstore.Spec.EnvJWTSECRET:     []byte("secret"),
+		},
+	}
+}
+
+// newOwnedDeployment returns a Deployment named in the spec of bookstore
+// and controlled by it.
+func newOwnedDeployment(bookstore *samplev1alpha1.Bookstore) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bookstore.Spec.DeploymentName,
+			Namespace: bookstore.Namespace,
+			UID:       types.UID(bookstore.Spec.DeploymentName + "-uid"),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(bookstore, samplev1alpha1.SchemeGroupVersion.WithKind("Bookstore")),
+			},
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: bookstore.Spec.Replicas},
+	}
+}
+
+func newFixture(t *testing.T) *fixture.Fixture {
+	return fixture.New(t)
+}
+
+// reconcile returns a sync that reconciles the Bookstore with key, with a
+// reconciler built from the fake clients and informers of f.
+func reconcile(ctx context.Context, f *fixture.Fixture, key string) func() error {
+	return func() error {
+		r := NewReconciler(f.KubeClientset(), f.Client,
+			f.KubeInformerFactory.Apps().V1().Deployments(),
+			f.KubeInformerFactory.Core().V1().Services(),
+			f.KubeInformerFactory.Core().V1().Secrets(),
+			f.KubeInformerFactory.Core().V1().ConfigMaps(),
+			f.InformerFactory.Calico().V1alpha1().Bookstores(),
+			f.Recorder, true)
+		_, err := r.Reconcile(ctx, key)
+		return err
+	}
+}
+
+func getKey(bookstore *samplev1alpha1.Bookstore, t *testing.T) string {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(bookstore)
+	if err != nil {
+		t.Errorf("Unexpected error getting key for bookstore %v: %v", bookstore.Name, err)
+		return ""
+	}
+	return key
+}
+
+// expectStatus expects the status of bookstore to be applied as status.
+func expectStatus(f *fixture.Fixture, bookstore *samplev1alpha1.Bookstore, status samplev1alpha1.BookstoreStatus) {
+	applied := samplev1alpha1ac.BookstoreStatus().
+		WithObservedGeneration(status.ObservedGeneration).
+		WithReplicas(status.Replicas).
+		WithAvailableReplicas(status.AvailableReplicas).
+		WithSelector(status.Selector).
+		WithSecretName(status.SecretName)
+	for _, c := range status.Conditions {
+		applied.WithConditions(metav1ac.Condition().
+			WithType(c.Type).
+			WithStatus(c.Status).
+			WithObservedGeneration(c.ObservedGeneration).
+			WithLastTransitionTime(c.LastTransitionTime).
+			WithReason(c.Reason).
+			WithMessage(c.Message))
+	}
+	f.ExpectApply(fixture.BookstoresResource, bookstore.Namespace, bookstore.Name,
+		samplev1alpha1ac.Bookstore(bookstore.Name, bookstore.Namespace).WithStatus(applied), "status")
+}
+
+// condition returns a condition observed at the first generation, which
+// every test Bookstore is at.
+func condition(conditionType string, status metav1.ConditionStatus, reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: 1,
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// The messages of the conditions that do not depend on the test.
+const (
+	noConflictMessage  = "All child resources are managed by this Bookstore"
+	secretFoundMessage = "The credentials Secret holds every key read by the Bookstore"
+	testSelector       = "app=test-app,controller=test-customController1"
+)
+
+func TestCreatesDeploymentAndService(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	secret := newSecret(bookstore)
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+
+	f.ExpectKubeApply(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, newDeployment(bookstore, podConfigHash(bookstore, secret, nil), true))
+	f.ExpectKubeApply(fixture.ServicesResource, bookstore.Namespace, bookstore.Spec.ServiceName, newService(bookstore))
+	expectStatus(f, bookstore, samplev1alpha1.BookstoreStatus{
+		ObservedGeneration: 1,
+		Selector:           testSelector,
+		SecretName:         "env-secrets",
+		Conditions: []metav1.Condition{
+			condition("ResourceConflict", metav1.ConditionFalse, "NoConflict", noConflictMessage),
+			condition("SecretMissing", metav1.ConditionFalse, "SecretFound", secretFoundMessage),
+			condition("Failed", metav1.ConditionFalse, "Synced", "Bookstore synced successfully"),
+			condition("Degraded", metav1.ConditionFalse, "Synced", "Bookstore synced successfully"),
+			condition("Progressing", metav1.ConditionTrue, "RolloutInProgress", "0 of 1 replicas available"),
+			condition("Ready", metav1.ConditionFalse, "RolloutInProgress", "0 of 1 replicas available"),
+		},
+	})
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+func TestAddsFinalizer(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	bookstore.Finalizers = nil
+	secret := newSecret(bookstore)
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+
+	f.ExpectPatch(fixture.BookstoresResource, bookstore.Namespace, bookstore.Name, types.MergePatchType,
+		[]byte(fmt.Sprintf(`{"metadata":{"finalizers":[%q],"resourceVersion":""}}`, BookstoreFinalizer)))
+	f.ExpectKubeApply(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, newDeployment(bookstore, podConfigHash(bookstore, secret, nil), true))
+	f.ExpectKubeApply(fixture.ServicesResource, bookstore.Namespace, bookstore.Spec.ServiceName, newService(bookstore))
+	expectStatus(f, bookstore, samplev1alpha1.BookstoreStatus{
+		ObservedGeneration: 1,
+		Selector:           testSelector,
+		SecretName:         "env-secrets",
+		Conditions: []metav1.Condition{
+			condition("ResourceConflict", metav1.ConditionFalse, "NoConflict", noConflictMessage),
+			condition("SecretMissing", metav1.ConditionFalse, "SecretFound", secretFoundMessage),
+			condition("Failed", metav1.ConditionFalse, "Synced", "Bookstore synced successfully"),
+			condition("Degraded", metav1.ConditionFalse, "Synced", "Bookstore synced successfully"),
+			condition("Progressing", metav1.ConditionTrue, "RolloutInProgress", "0 of 1 replicas available"),
+			condition("Ready", metav1.ConditionFalse, "RolloutInProgress", "0 of 1 replicas available"),
+		},
+	})
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+func TestSecretMissing(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+
+	message := `secret "env-secrets" not found`
+	expectStatus(f, bookstore, samplev1alpha1.BookstoreStatus{
+		ObservedGeneration: 1,
+		Selector:           testSelector,
+		SecretName:         "env-secrets",
+		Conditions: []metav1.Condition{
+			condition("ResourceConflict", metav1.ConditionFalse, "NoConflict", noConflictMessage),
+			condition("SecretMissing", metav1.ConditionTrue, "SecretNotFound", message),
+			condition("Failed", metav1.ConditionFalse, "Retrying", "The last sync failed and is retried: "+message),
+			condition("Degraded", metav1.ConditionTrue, "SecretNotFound", message),
+			condition("Progressing", metav1.ConditionFalse, "SecretNotFound", message),
+			condition("Ready", metav1.ConditionFalse, "SecretNotFound", message),
+		},
+	})
+	f.ExpectEvent(corev1.EventTypeWarning, SecretMissing, message)
+
+	f.RunExpectError(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+func TestNotControlledByUs(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	secret := newSecret(bookstore)
+	deployment := newOwnedDeployment(bookstore)
+	deployment.OwnerReferences = nil
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+	f.DeploymentLister = append(f.DeploymentLister, deployment)
+
+	message := `Resource "test-deployment" already exists and is not managed by Bookstore`
+	expectStatus(f, bookstore, samplev1alpha1.BookstoreStatus{
+		ObservedGeneration: 1,
+		Selector:           testSelector,
+		SecretName:         "env-secrets",
+		Conditions: []metav1.Condition{
+			condition("ResourceConflict", metav1.ConditionTrue, "ErrResourceExists", message),
+			condition("SecretMissing", metav1.ConditionFalse, "SecretFound", secretFoundMessage),
+			condition("Failed", metav1.ConditionTrue, "ErrResourceExists", message),
+			condition("Degraded", metav1.ConditionTrue, "ErrResourceExists", message),
+			condition("Progressing", metav1.ConditionFalse, "ErrResourceExists", message),
+			condition("Ready", metav1.ConditionFalse, "ErrResourceExists", message),
+		},
+	})
+	f.ExpectEvent(corev1.EventTypeWarning, ErrResourceExists, message)
+
+	err := f.RunExpectError(reconcile(ctx, f, getKey(bookstore, t)))
+	var conflict *resourceConflictError
+	if !errors.As(err, &conflict) || conflict.name != deployment.Name {
+		t.Errorf("expected a resource conflict on %q, got %v", deployment.Name, err)
+	}
+	if class := ClassifyError(err); class != ErrorTerminal {
+		t.Errorf("expected a terminal error, got class %d", class)
+	}
+}
+
+// TestAdoptsAfterConflict checks that a Bookstore that failed on a
+// Deployment it does not control is synced again, without a spec change,
+// once it is annotated for adoption.
+func TestAdoptsAfterConflict(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	bookstore.Annotations = map[string]string{samplev1alpha1.AdoptAnnotation: "true"}
+	bookstore.Status.Conditions = []metav1.Condition{{
+		Type:               samplev1alpha1.BookstoreResourceConflict,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: bookstore.Generation,
+		Reason:             ErrResourceExists,
+	}, {
+		Type:               samplev1alpha1.BookstoreFailed,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: bookstore.Generation,
+		Reason:             ErrResourceExists,
+	}}
+	secret := newSecret(bookstore)
+	deployment := newOwnedDeployment(bookstore)
+	deployment.OwnerReferences = nil
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: bookstore.GetSelectorLabels()}
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+	f.DeploymentLister = append(f.DeploymentLister, deployment)
+
+	f.ExpectKubePatch(fixture.DeploymentsResource, deployment.Namespace, deployment.Name, types.StrategicMergePatchType,
+		[]byte(fmt.Sprintf(`{"metadata":{"ownerReferences":[{"apiVersion":"calico.com/v1alpha1","kind":"Bookstore","name":"test","uid":"test-uid","controller":true,"blockOwnerDeletion":true}],"uid":%q}}`, deployment.UID)))
+	desired := newDeployment(bookstore, podConfigHash(bookstore, secret, nil), true)
+	desired.Spec.WithSelector(labelSelector(deployment.Spec.Selector))
+	f.ExpectKubeApply(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, desired)
+	f.ExpectKubeApply(fixture.ServicesResource, bookstore.Namespace, bookstore.Spec.ServiceName, newService(bookstore))
+	expectStatus(f, bookstore, samplev1alpha1.BookstoreStatus{
+		ObservedGeneration: 1,
+		Selector:           testSelector,
+		SecretName:         "env-secrets",
+		Conditions: []metav1.Condition{
+			condition("ResourceConflict", metav1.ConditionFalse, "NoConflict", noConflictMessage),
+			condition("Failed", metav1.ConditionFalse, "Synced", "Bookstore synced successfully"),
+			condition("SecretMissing", metav1.ConditionFalse, "SecretFound", secretFoundMessage),
+			condition("Degraded", metav1.ConditionFalse, "Synced", "Bookstore synced successfully"),
+			condition("Progressing", metav1.ConditionTrue, "RolloutInProgress", "0 of 1 replicas available"),
+			condition("Ready", metav1.ConditionFalse, "RolloutInProgress", "0 of 1 replicas available"),
+		},
+	})
+	f.ExpectEvent(corev1.EventTypeNormal, Adopted, fmt.Sprintf(MessageAdopted, "Deployment", deployment.Name))
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+func TestSkipsFailedBookstore(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	meta.SetStatusCondition(&bookstore.Status.Conditions, metav1.Condition{
+		Type:               samplev1alpha1.BookstoreFailed,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: bookstore.Generation,
+		Reason:             ReasonInvalidSpec,
+	})
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+// failedOnConflict marks bookstore as failed because a child belonged to
+// someone else.
+func failedOnConflict(bookstore *samplev1alpha1.Bookstore) {
+	meta.SetStatusCondition(&bookstore.Status.Conditions, metav1.Condition{
+		Type:               samplev1alpha1.BookstoreFailed,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: bookstore.Generation,
+		Reason:             ErrResourceExists,
+	})
+}
+
+// TestSkipsBookstoreWithConflictingChild checks that a Bookstore that failed
+// on a Deployment it does not control is not synced again while the
+// Deployment is still there.
+func TestSkipsBookstoreWithConflictingChild(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	failedOnConflict(bookstore)
+	deployment := newOwnedDeployment(bookstore)
+	deployment.OwnerReferences = nil
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, newSecret(bookstore))
+	f.DeploymentLister = append(f.DeploymentLister, deployment)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+// TestSyncsAfterConflictingChildDeleted checks that a Bookstore that failed
+// on a Deployment it does not control is synced again, without a spec
+// change, once the Deployment is deleted.
+func TestSyncsAfterConflictingChildDeleted(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	failedOnConflict(bookstore)
+	secret := newSecret(bookstore)
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+
+	f.ExpectKubeApply(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, newDeployment(bookstore, podConfigHash(bookstore, secret, nil), true))
+	f.ExpectKubeApply(fixture.ServicesResource, bookstore.Namespace, bookstore.Spec.ServiceName, newService(bookstore))
+	expectStatus(f, bookstore, samplev1alpha1.BookstoreStatus{
+		ObservedGeneration: 1,
+		Selector:           testSelector,
+		SecretName:         "env-secrets",
+		Conditions: []metav1.Condition{
+			condition("Failed", metav1.ConditionFalse, "Synced", "Bookstore synced successfully"),
+			condition("ResourceConflict", metav1.ConditionFalse, "NoConflict", noConflictMessage),
+			condition("SecretMissing", metav1.ConditionFalse, "SecretFound", secretFoundMessage),
+			condition("Degraded", metav1.ConditionFalse, "Synced", "Bookstore synced successfully"),
+			condition("Progressing", metav1.ConditionTrue, "RolloutInProgress", "0 of 1 replicas available"),
+			condition("Ready", metav1.ConditionFalse, "RolloutInProgress", "0 of 1 replicas available"),
+		},
+	})
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+func TestOrphansChildrenOnDeletion(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	bookstore.Spec.DeletionPolicy = samplev1alpha1.DeletionPolicyOrphan
+	bookstore.DeletionTimestamp = ptr.To(metav1.Now())
+	deployment := newOwnedDeployment(bookstore)
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.DeploymentLister = append(f.DeploymentLister, deployment)
+
+	f.ExpectKubePatch(fixture.DeploymentsResource, deployment.Namespace, deployment.Name, types.StrategicMergePatchType, orphanPatch(bookstore, deployment))
+	f.ExpectPatch(fixture.BookstoresResource, bookstore.Namespace, bookstore.Name, types.MergePatchType,
+		[]byte(`{"metadata":{"finalizers":[],"resourceVersion":""}}`))
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+// TestScalesDownRenamedDeploymentOnDeletion checks that the Delete policy
+// scales down the Deployment of a previous deploymentName that was not
+// pruned yet along with the current one, and keeps the finalizer until
+// their pods are gone.
+func TestScalesDownRenamedDeploymentOnDeletion(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	bookstore.DeletionTimestamp = ptr.To(metav1.Now())
+	secret := newSecret(bookstore)
+	old := appliedDeployment(t, bookstore, secret)
+	old.Status.Replicas = 1
+	bookstore.Spec.DeploymentName = "test-deployment-v2"
+	deployment := appliedDeployment(t, bookstore, secret)
+	deployment.Status.Replicas = 1
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.DeploymentLister = append(f.DeploymentLister, deployment, old)
+
+	f.ExpectKubePatch(fixture.DeploymentsResource, old.Namespace, old.Name, types.MergePatchType, []byte(`{"spec":{"replicas":0}}`))
+	f.ExpectKubePatch(fixture.DeploymentsResource, deployment.Namespace, deployment.Name, types.MergePatchType, []byte(`{"spec":{"replicas":0}}`))
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+// TestRetainsRenamedChildrenOnDeletion checks that the Retain policy orphans
+// the Deployment and Service of a previous deploymentName and serviceName
+// that were not pruned yet along with the current ones, once all of them
+// are scaled down.
+func TestRetainsRenamedChildrenOnDeletion(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](0))
+	bookstore.Spec.DeletionPolicy = samplev1alpha1.DeletionPolicyRetain
+	bookstore.DeletionTimestamp = ptr.To(metav1.Now())
+	secret := newSecret(bookstore)
+	oldDeployment := appliedDeployment(t, bookstore, secret)
+	oldService := appliedService(t, bookstore)
+	bookstore.Spec.DeploymentName = "test-deployment-v2"
+	bookstore.Spec.ServiceName = "test-service-v2"
+	deployment := appliedDeployment(t, bookstore, secret)
+	service := appliedService(t, bookstore)
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.DeploymentLister = append(f.DeploymentLister, deployment, oldDeployment)
+	f.ServiceLister = append(f.ServiceLister, service, oldService)
+
+	f.ExpectKubePatch(fixture.DeploymentsResource, oldDeployment.Namespace, oldDeployment.Name, types.StrategicMergePatchType, orphanPatch(bookstore, oldDeployment))
+	f.ExpectKubePatch(fixture.DeploymentsResource, deployment.Namespace, deployment.Name, types.StrategicMergePatchType, orphanPatch(bookstore, deployment))
+	f.ExpectKubePatch(fixture.ServicesResource, oldService.Namespace, oldService.Name, types.StrategicMergePatchType, orphanPatch(bookstore, oldService))
+	f.ExpectKubePatch(fixture.ServicesResource, service.Namespace, service.Name, types.StrategicMergePatchType, orphanPatch(bookstore, service))
+	f.ExpectPatch(fixture.BookstoresResource, bookstore.Namespace, bookstore.Name, types.MergePatchType,
+		[]byte(`{"metadata":{"finalizers":[],"resourceVersion":""}}`))
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+func TestAppliesPodTemplate(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	bookstore.Spec.PodTemplate = &runtime.RawExtension{
+		Raw: []byte(`{"spec":{"nodeSelector":{"disktype":"ssd"},"containers":[{"name":"test-deployment","workingDir":"/srv"}]}}`),
+	}
+	bookstore.Spec.DeploymentName = "test-deployment"
+	secret := newSecret(bookstore)
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+
+	deployment := newDeployment(bookstore, podConfigHash(bookstore, secret, nil), true)
+	deployment.Spec.Template.Spec.WithNodeSelector(map[string]string{"disktype": "ssd"})
+	deployment.Spec.Template.Spec.Containers[0].WithWorkingDir("/srv")
+	f.ExpectKubeApply(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, deployment)
+	f.ExpectKubeApply(fixture.ServicesResource, bookstore.Namespace, bookstore.Spec.ServiceName, newService(bookstore))
+	expectStatus(f, bookstore, samplev1alpha1.BookstoreStatus{
+		ObservedGeneration: 1,
+		Selector:           testSelector,
+		SecretName:         "env-secrets",
+		Conditions: []metav1.Condition{
+			condition("ResourceConflict", metav1.ConditionFalse, "NoConflict", noConflictMessage),
+			condition("SecretMissing", metav1.ConditionFalse, "SecretFound", secretFoundMessage),
+			condition("Failed", metav1.ConditionFalse, "Synced", "Bookstore synced successfully"),
+			condition("Degraded", metav1.ConditionFalse, "Synced", "Bookstore synced successfully"),
+			condition("Progressing", metav1.ConditionTrue, "RolloutInProgress", "0 of 1 replicas available"),
+			condition("Ready", metav1.ConditionFalse, "RolloutInProgress", "0 of 1 replicas available"),
+		},
+	})
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+func TestRejectsPodTemplateChangingSelector(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	bookstore.Spec.PodTemplate = &runtime.RawExtension{
+		Raw: []byte(`{"metadata":{"labels":{"app":"other"}}}`),
+	}
+	secret := newSecret(bookstore)
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+
+	message := "spec.podTemplate.metadata.labels[app]: Forbidden: may not change the selector label app=test-app"
+	expectStatus(f, bookstore, samplev1alpha1.BookstoreStatus{
+		ObservedGeneration: 1,
+		Selector:           testSelector,
+		SecretName:         "env-secrets",
+		Conditions: []metav1.Condition{
+			condition("ResourceConflict", metav1.ConditionFalse, "NoConflict", noConflictMessage),
+			condition("SecretMissing", metav1.ConditionFalse, "SecretFound", secretFoundMessage),
+			condition("Failed", metav1.ConditionTrue, "InvalidSpec", message),
+			condition("Degraded", metav1.ConditionTrue, "InvalidSpec", message),
+			condition("Progressing", metav1.ConditionFalse, "InvalidSpec", message),
+			condition("Ready", metav1.ConditionFalse, "InvalidSpec", message),
+		},
+	})
+
+	f.RunExpectError(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+// TestRejectsPodTemplateSettingConfigHash checks that the overlay cannot
+// set the annotation that rolls the pods when their data changes.
+func TestRejectsPodTemplateSettingConfigHash(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	bookstore.Spec.PodTemplate = &runtime.RawExtension{
+		Raw: []byte(`{"metadata":{"annotations":{"calico.com/config-hash":"pinned"}}}`),
+	}
+	secret := newSecret(bookstore)
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+
+	message := "spec.podTemplate.metadata.annotations[calico.com/config-hash]: Forbidden: is set by the controller"
+	expectStatus(f, bookstore, samplev1alpha1.BookstoreStatus{
+		ObservedGeneration: 1,
+		Selector:           testSelector,
+		SecretName:         "env-secrets",
+		Conditions: []metav1.Condition{
+			condition("ResourceConflict", metav1.ConditionFalse, "NoConflict", noConflictMessage),
+			condition("SecretMissing", metav1.ConditionFalse, "SecretFound", secretFoundMessage),
+			condition("Failed", metav1.ConditionTrue, "InvalidSpec", message),
+			condition("Degraded", metav1.ConditionTrue, "InvalidSpec", message),
+			condition("Progressing", metav1.ConditionFalse, "InvalidSpec", message),
+			condition("Ready", metav1.ConditionFalse, "InvalidSpec", message),
+		},
+	})
+
+	err := f.RunExpectError(reconcile(ctx, f, getKey(bookstore, t)))
+	if class := ClassifyError(err); class != ErrorTerminal {
+		t.Errorf("expected an invalid overlay to be terminal, got class %d", class)
+	}
+}
+
+// TestRefusesAdoptionOfForeignSelector checks that a Deployment selecting
+// other pods than the Bookstore's is not adopted, since its selector cannot
+// be changed to the Bookstore's.
+func TestRefusesAdoptionOfForeignSelector(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	bookstore.Annotations = map[string]string{samplev1alpha1.AdoptAnnotation: "true"}
+	secret := newSecret(bookstore)
+	deployment := newOwnedDeployment(bookstore)
+	deployment.OwnerReferences = nil
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}}
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+	f.DeploymentLister = append(f.DeploymentLister, deployment)
+
+	message := `Resource "test-deployment" already exists and is not managed by Bookstore: its selector app=other does not match the Bookstore pods app=test-app,controller=test-customController1`
+	expectStatus(f, bookstore, samplev1alpha1.BookstoreStatus{
+		ObservedGeneration: 1,
+		Selector:           testSelector,
+		SecretName:         "env-secrets",
+		Conditions: []metav1.Condition{
+			condition("ResourceConflict", metav1.ConditionTrue, "ErrResourceExists", message),
+			condition("SecretMissing", metav1.ConditionFalse, "SecretFound", secretFoundMessage),
+			condition("Failed", metav1.ConditionTrue, "ErrResourceExists", message),
+			condition("Degraded", metav1.ConditionTrue, "ErrResourceExists", message),
+			condition("Progressing", metav1.ConditionFalse, "ErrResourceExists", message),
+			condition("Ready", metav1.ConditionFalse, "ErrResourceExists", message),
+		},
+	})
+	f.ExpectEvent(corev1.EventTypeWarning, ErrResourceExists, message)
+
+	f.RunExpectError(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+// inProgressStatus is the status of a Bookstore synced successfully while
+// the single replica of its Deployment is not available yet.
+func inProgressStatus() samplev1alpha1.BookstoreStatus {
+	return samplev1alpha1.BookstoreStatus{
+		ObservedGeneration: 1,
+		Selector:           testSelector,
+		SecretName:         "env-secrets",
+		Conditions: []metav1.Condition{
+			condition("ResourceConflict", metav1.ConditionFalse, "NoConflict", noConflictMessage),
+			condition("SecretMissing", metav1.ConditionFalse, "SecretFound", secretFoundMessage),
+			condition("Failed", metav1.ConditionFalse, "Synced", "Bookstore synced successfully"),
+			condition("Degraded", metav1.ConditionFalse, "Synced", "Bookstore synced successfully"),
+			condition("Progressing", metav1.ConditionTrue, "RolloutInProgress", "0 of 1 replicas available"),
+			condition("Ready", metav1.ConditionFalse, "RolloutInProgress", "0 of 1 replicas available"),
+		},
+	}
+}
+
+// appliedDeployment returns the Deployment of bookstore as the controller
+// last applied it, with the pod template hashing secret.
+func appliedDeployment(t *testing.T, bookstore *samplev1alpha1.Bookstore, secret *corev1.Secret) *appsv1.Deployment {
+	return fixture.Apply(t, nil, FieldManager, newDeployment(bookstore, podConfigHash(bookstore, secret, nil), true)).(*appsv1.Deployment)
+}
+
+// appliedService returns the Service of bookstore as the controller last
+// applied it.
+func appliedService(t *testing.T, bookstore *samplev1alpha1.Bookstore) *corev1.Service {
+	return fixture.Apply(t, nil, FieldManager, newService(bookstore)).(*corev1.Service)
+}
+
+// TestAppliesOnlyOnDrift checks that the Deployment is applied when, and
+// only when, a field the controller applied differs from the Bookstore,
+// going by the managedFields of the live Deployment. Fields other managers
+// add are left alone.
+func TestAppliesOnlyOnDrift(t *testing.T) {
+	tests := []struct {
+		name string
+		// edit changes the Deployment last applied by the controller.
+		edit func(t *testing.T, deployment *appsv1.Deployment) *appsv1.Deployment
+		// image is the image of the Bookstore spec.
+		image string
+		apply bool
+	}{{
+		name:  "unchanged",
+		edit:  func(t *testing.T, deployment *appsv1.Deployment) *appsv1.Deployment { return deployment },
+		image: "latest",
+	}, {
+		name: "field added by another manager",
+		edit: func(t *testing.T, deployment *appsv1.Deployment) *appsv1.Deployment {
+			sidecar := appsv1ac.Deployment("test-deployment", metav1.NamespaceDefault).
+				WithSpec(appsv1ac.DeploymentSpec().
+					WithTemplate(corev1ac.PodTemplateSpec().
+						WithAnnotations(map[string]string{"sidecar.example.com/inject": "true"})))
+			return fixture.Apply(t, deployment, "mesh-injector", sidecar).(*appsv1.Deployment)
+		},
+		image: "latest",
+	}, {
+		name:  "spec changed",
+		edit:  func(t *testing.T, deployment *appsv1.Deployment) *appsv1.Deployment { return deployment },
+		image: "v2",
+		apply: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			bookstore := newBookstore("test", ptr.To[int32](1))
+			secret := newSecret(bookstore)
+			deployment := tt.edit(t, appliedDeployment(t, bookstore, secret))
+			bookstore.Spec.DeploymentImageTag = tt.image
+			_, ctx := ktesting.NewTestContext(t)
+
+			f.BookstoreLister = append(f.BookstoreLister, bookstore)
+			f.SecretLister = append(f.SecretLister, secret)
+			f.DeploymentLister = append(f.DeploymentLister, deployment)
+			f.ServiceLister = append(f.ServiceLister, appliedService(t, bookstore))
+
+			desired := newDeployment(bookstore, podConfigHash(bookstore, secret, nil), true)
+			if tt.apply {
+				f.ExpectKubeApply(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, desired)
+			}
+			expectStatus(f, bookstore, inProgressStatus())
+			f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+			f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+
+			live, err := f.KubeClient.AppsV1().Deployments(bookstore.Namespace).Get(ctx, bookstore.Spec.DeploymentName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("error getting deployment: %v", err)
+			}
+			if drifted, err := deploymentDrifted(desired, live); err != nil || drifted {
+				t.Errorf("expected the deployment to match the bookstore after the sync, drifted %t: %v", drifted, err)
+			}
+		})
+	}
+}
+
+// TestAdoptedDeploymentIsAppliedOver checks that the controller takes over
+// the fields of an adopted Deployment that another manager set.
+func TestAdoptedDeploymentIsAppliedOver(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	bookstore.Annotations = map[string]string{samplev1alpha1.AdoptAnnotation: "true"}
+	secret := newSecret(bookstore)
+	handmade := newDeployment(bookstore, "", true)
+	handmade.OwnerReferences = nil
+	handmade.Spec.Template.Spec.Containers[0].WithImage("bookstore:handmade")
+	deployment := fixture.Apply(t, nil, "kubectl", handmade).(*appsv1.Deployment)
+	deployment.UID = "test-deployment-uid"
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+	f.DeploymentLister = append(f.DeploymentLister, deployment)
+	f.ServiceLister = append(f.ServiceLister, appliedService(t, bookstore))
+
+	f.ExpectKubePatch(fixture.DeploymentsResource, deployment.Namespace, deployment.Name, types.StrategicMergePatchType,
+		[]byte(`{"metadata":{"ownerReferences":[{"apiVersion":"calico.com/v1alpha1","kind":"Bookstore","name":"test","uid":"test-uid","controller":true,"blockOwnerDeletion":true}],"uid":"test-deployment-uid"}}`))
+	desired := newDeployment(bookstore, podConfigHash(bookstore, secret, nil), true)
+	f.ExpectKubeApply(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, desired)
+	expectStatus(f, bookstore, inProgressStatus())
+	f.ExpectEvent(corev1.EventTypeNormal, Adopted, `Deployment "test-deployment" adopted`)
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+
+	live, err := f.KubeClient.AppsV1().Deployments(bookstore.Namespace).Get(ctx, bookstore.Spec.DeploymentName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting deployment: %v", err)
+	}
+	if image := live.Spec.Template.Spec.Containers[0].Image; image != "bookstore:latest" {
+		t.Errorf("expected the adopted deployment to run bookstore:latest, got %s", image)
+	}
+	if drifted, err := deploymentDrifted(desired, live); err != nil || drifted {
+		t.Errorf("expected the controller to own every field it applied, drifted %t: %v", drifted, err)
+	}
+}
+
+// reclaimPatch is the patch that takes back the fields of the Deployment of
+// a Bookstore named test that the controller must own.
+const reclaimPatch = `{"metadata":{"labels":{"app.kubernetes.io/managed-by":"sample-controller"},` +
+	`"ownerReferences":[{"apiVersion":"calico.com/v1alpha1","kind":"Bookstore","name":"test","uid":"test-uid","controller":true,"blockOwnerDeletion":true}]},` +
+	`"spec":{"template":{"metadata":{"labels":{"app":"test-app","controller":"test-customController1"}}}}}`
+
+// TestReportsFieldConflict checks that a field the Bookstore sets but
+// another manager owns is left to it, and reported, instead of taken over.
+func TestReportsFieldConflict(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	secret := newSecret(bookstore)
+	deployment := appliedDeployment(t, bookstore, secret)
+	scale := appsv1ac.Deployment("test-deployment", metav1.NamespaceDefault).
+		WithSpec(appsv1ac.DeploymentSpec().WithReplicas(3))
+	deployment = fixture.Apply(t, deployment, "horizontal-pod-autoscaler", scale).(*appsv1.Deployment)
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+	f.DeploymentLister = append(f.DeploymentLister, deployment)
+	f.ServiceLister = append(f.ServiceLister, appliedService(t, bookstore))
+
+	desired := newDeployment(bookstore, podConfigHash(bookstore, secret, nil), true)
+	f.ExpectKubeApply(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, desired)
+	f.ExpectKubePatch(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, types.StrategicMergePatchType, []byte(reclaimPatch))
+	f.ExpectKubeApply(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, desired)
+	message := `Deployment "test-deployment" has fields managed by someone else: Apply failed with 1 conflict: conflict with "horizontal-pod-autoscaler": .spec.replicas`
+	expectStatus(f, bookstore, samplev1alpha1.BookstoreStatus{
+		ObservedGeneration: 1,
+		Selector:           testSelector,
+		SecretName:         "env-secrets",
+		Conditions: []metav1.Condition{
+			condition("ResourceConflict", metav1.ConditionTrue, "FieldConflict", message),
+			condition("SecretMissing", metav1.ConditionFalse, "SecretFound", secretFoundMessage),
+			condition("Failed", metav1.ConditionFalse, "Retrying", "The last sync failed and is retried: "+message),
+			condition("Degraded", metav1.ConditionTrue, "FieldConflict", message),
+			condition("Progressing", metav1.ConditionFalse, "FieldConflict", message),
+			condition("Ready", metav1.ConditionFalse, "FieldConflict", message),
+		},
+	})
+	f.ExpectEvent(corev1.EventTypeWarning, FieldConflict, message)
+
+	err := f.RunExpectError(reconcile(ctx, f, getKey(bookstore, t)))
+	if class := ClassifyError(err); class != ErrorTransient {
+		t.Errorf("expected a field conflict to be retried with backoff, got class %d", class)
+	}
+	live, err := f.KubeClient.AppsV1().Deployments(bookstore.Namespace).Get(ctx, bookstore.Spec.DeploymentName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting deployment: %v", err)
+	}
+	if replicas := *live.Spec.Replicas; replicas != 3 {
+		t.Errorf("expected the deployment to keep the 3 replicas of the autoscaler, got %d", replicas)
+	}
+}
+
+// TestReclaimsOwnedFields checks that the fields that tie a Deployment to
+// its Bookstore are taken back from another manager.
+func TestReclaimsOwnedFields(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	secret := newSecret(bookstore)
+	deployment := appliedDeployment(t, bookstore, secret)
+	relabel := appsv1ac.Deployment("test-deployment", metav1.NamespaceDefault).
+		WithLabels(map[string]string{ManagedByLabel: "helm"})
+	deployment = fixture.Apply(t, deployment, "helm", relabel).(*appsv1.Deployment)
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+	f.DeploymentLister = append(f.DeploymentLister, deployment)
+	f.ServiceLister = append(f.ServiceLister, appliedService(t, bookstore))
+
+	desired := newDeployment(bookstore, podConfigHash(bookstore, secret, nil), true)
+	f.ExpectKubeApply(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, desired)
+	f.ExpectKubePatch(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, types.StrategicMergePatchType, []byte(reclaimPatch))
+	f.ExpectKubeApply(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, desired)
+	expectStatus(f, bookstore, inProgressStatus())
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+
+	live, err := f.KubeClient.AppsV1().Deployments(bookstore.Namespace).Get(ctx, bookstore.Spec.DeploymentName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting deployment: %v", err)
+	}
+	if label := live.Labels[ManagedByLabel]; label != FieldManager {
+		t.Errorf("expected the deployment to be labeled as managed by %s, got %s", FieldManager, label)
+	}
+}
+
+// TestLeavesReplicasToAutoscaler checks that a Bookstore without replicas
+// does not apply them, so that a HorizontalPodAutoscaler can scale it.
+func TestLeavesReplicasToAutoscaler(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", nil)
+	secret := newSecret(bookstore)
+	deployment := appliedDeployment(t, bookstore, secret)
+	scale := appsv1ac.Deployment("test-deployment", metav1.NamespaceDefault).
+		WithSpec(appsv1ac.DeploymentSpec().WithReplicas(3))
+	deployment = fixture.Apply(t, deployment, "horizontal-pod-autoscaler", scale).(*appsv1.Deployment)
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+	f.DeploymentLister = append(f.DeploymentLister, deployment)
+	f.ServiceLister = append(f.ServiceLister, appliedService(t, bookstore))
+
+	if replicas := newDeployment(bookstore, "", true).Spec.Replicas; replicas != nil {
+		t.Fatalf("expected no replicas to be applied, got %d", *replicas)
+	}
+	status := inProgressStatus()
+	status.Conditions[4] = condition("Progressing", metav1.ConditionTrue, "RolloutInProgress", "0 of 3 replicas available")
+	status.Conditions[5] = condition("Ready", metav1.ConditionFalse, "RolloutInProgress", "0 of 3 replicas available")
+	expectStatus(f, bookstore, status)
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+// TestAppliesReplicasFromScale checks that replicas written to a Bookstore
+// that had none, as through its scale subresource, are applied to its
+// Deployment.
+func TestAppliesReplicasFromScale(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", nil)
+	secret := newSecret(bookstore)
+	deployment := appliedDeployment(t, bookstore, secret)
+	bookstore.Spec.Replicas = ptr.To[int32](3)
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+	f.DeploymentLister = append(f.DeploymentLister, deployment)
+	f.ServiceLister = append(f.ServiceLister, appliedService(t, bookstore))
+
+	f.ExpectKubeApply(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, newDeployment(bookstore, podConfigHash(bookstore, secret, nil), true))
+	status := inProgressStatus()
+	status.Conditions[4] = condition("Progressing", metav1.ConditionTrue, "RolloutInProgress", "0 of 3 replicas available")
+	status.Conditions[5] = condition("Ready", metav1.ConditionFalse, "RolloutInProgress", "0 of 3 replicas available")
+	expectStatus(f, bookstore, status)
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+
+	live, err := f.KubeClient.AppsV1().Deployments(bookstore.Namespace).Get(ctx, bookstore.Spec.DeploymentName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting deployment: %v", err)
+	}
+	if live.Spec.Replicas == nil || *live.Spec.Replicas != 3 {
+		t.Errorf("expected the deployment to be scaled to 3 replicas, got %v", live.Spec.Replicas)
+	}
+}
+
+// TestRecreatesServiceOnTypeChange checks that a Service whose type cannot
+// change in place is deleted, and that the new Service is only applied by
+// the sync that no longer finds the old one.
+func TestRecreatesServiceOnTypeChange(t *testing.T) {
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	secret := newSecret(bookstore)
+	nodePort := bookstore.DeepCopy()
+	nodePort.Spec.ServiceType = string(corev1.ServiceTypeNodePort)
+	nodePort.Spec.NodePort = 30001
+	old := appliedService(t, nodePort)
+	_, ctx := ktesting.NewTestContext(t)
+
+	f := newFixture(t)
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+	f.DeploymentLister = append(f.DeploymentLister, appliedDeployment(t, bookstore, secret))
+	f.ServiceLister = append(f.ServiceLister, old)
+
+	f.ExpectKubeDelete(fixture.ServicesResource, bookstore.Namespace, bookstore.Spec.ServiceName)
+	message := `service "test-service" is being recreated`
+	status := inProgressStatus()
+	status.Conditions[2] = condition("Failed", metav1.ConditionFalse, "Retrying", "The last sync failed and is retried: "+message)
+	status.Conditions[3] = condition("Degraded", metav1.ConditionTrue, "SyncFailed", message)
+	status.Conditions[5] = condition("Ready", metav1.ConditionFalse, "SyncFailed", message)
+	expectStatus(f, bookstore, status)
+	f.ExpectEvent(corev1.EventTypeNormal, ServiceRecreated, fmt.Sprintf(MessageServiceRecreated, "test-service", "service type ClusterIP cannot keep the node ports allocated for type NodePort"))
+
+	err := f.RunExpectError(reconcile(ctx, f, getKey(bookstore, t)))
+	if class := ClassifyError(err); class != ErrorTransient {
+		t.Errorf("expected the sync to be retried until the service is gone, got class %d", class)
+	}
+
+	f = newFixture(t)
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+	f.DeploymentLister = append(f.DeploymentLister, appliedDeployment(t, bookstore, secret))
+
+	f.ExpectKubeApply(fixture.ServicesResource, bookstore.Namespace, bookstore.Spec.ServiceName, newService(bookstore))
+	expectStatus(f, bookstore, inProgressStatus())
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+// TestPrunesRenamedDeployment checks that the Deployment a Bookstore used
+// before deploymentName was renamed is deleted once the new one has rolled
+// out.
+func TestPrunesRenamedDeployment(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	secret := newSecret(bookstore)
+	old := appliedDeployment(t, bookstore, secret)
+	bookstore.Spec.DeploymentName = "test-deployment-v2"
+	deployment := appliedDeployment(t, bookstore, secret)
+	deployment.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+	f.DeploymentLister = append(f.DeploymentLister, old, deployment)
+	f.ServiceLister = append(f.ServiceLister, appliedService(t, bookstore))
+
+	f.ExpectKubeDelete(fixture.DeploymentsResource, old.Namespace, "test-deployment")
+	expectStatus(f, bookstore, samplev1alpha1.BookstoreStatus{
+		ObservedGeneration: 1,
+		Replicas:           1,
+		AvailableReplicas:  1,
+		Selector:           testSelector,
+		SecretName:         "env-secrets",
+		Conditions: []metav1.Condition{
+			condition("ResourceConflict", metav1.ConditionFalse, "NoConflict", noConflictMessage),
+			condition("SecretMissing", metav1.ConditionFalse, "SecretFound", secretFoundMessage),
+			condition("Failed", metav1.ConditionFalse, "Synced", "Bookstore synced successfully"),
+			condition("Degraded", metav1.ConditionFalse, "Synced", "Bookstore synced successfully"),
+			condition("Progressing", metav1.ConditionFalse, "RolloutComplete", "1 of 1 replicas available"),
+			condition("Ready", metav1.ConditionTrue, "RolloutComplete", "1 of 1 replicas available"),
+		},
+	})
+	f.ExpectEvent(corev1.EventTypeNormal, Pruned, `Deployment "test-deployment" deleted, replaced by "test-deployment-v2"`)
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+// TestRollsOutChangedSecret checks that a change of the credentials read by
+// the pods changes the config hash of the pod template, which rolls them.
+func TestRollsOutChangedSecret(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	secret := newSecret(bookstore)
+	deployment := appliedDeployment(t, bookstore, secret)
+	secret.Data[bookstore.Spec.EnvAdminPassword] = []byte("rotated")
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+	f.DeploymentLister = append(f.DeploymentLister, deployment)
+	f.ServiceLister = append(f.ServiceLister, appliedService(t, bookstore))
+
+	desired := newDeployment(bookstore, podConfigHash(bookstore, secret, nil), true)
+	if desired.Spec.Template.Annotations[ConfigHashAnnotation] == deployment.Spec.Template.Annotations[ConfigHashAnnotation] {
+		t.Fatalf("expected the config hash to change with the secret")
+	}
+	f.ExpectKubeApply(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, desired)
+	expectStatus(f, bookstore, inProgressStatus())
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+// TestRollsOutChangedConfigMapBinaryData checks that a change of the
+// binaryData of the ConfigMap of a Bookstore changes the config hash of the
+// pod template, which rolls its pods.
+func TestRollsOutChangedConfigMapBinaryData(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	bookstore.Spec.ConfigMapRef = &corev1.LocalObjectReference{Name: "test-config"}
+	secret := newSecret(bookstore)
+	configMap := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: metav1.NamespaceDefault},
+		Data:       map[string]string{"LOG_LEVEL": "info"},
+		BinaryData: map[string][]byte{"catalog.bin": {0x01}},
+	}
+	deployment := fixture.Apply(t, nil, FieldManager, newDeployment(bookstore, podConfigHash(bookstore, secret, configMap), true)).(*appsv1.Deployment)
+	configMap.BinaryData["catalog.bin"] = []byte{0x02}
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+	f.ConfigMapLister = append(f.ConfigMapLister, configMap)
+	f.DeploymentLister = append(f.DeploymentLister, deployment)
+	f.ServiceLister = append(f.ServiceLister, appliedService(t, bookstore))
+
+	desired := newDeployment(bookstore, podConfigHash(bookstore, secret, configMap), true)
+	if desired.Spec.Template.Annotations[ConfigHashAnnotation] == deployment.Spec.Template.Annotations[ConfigHashAnnotation] {
+		t.Fatalf("expected the config hash to change with the binary data of the config map")
+	}
+	f.ExpectKubeApply(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, desired)
+	expectStatus(f, bookstore, inProgressStatus())
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+// generatedSecret returns the Secret the controller generated for bookstore,
+// recording the given RegenerateSecretAnnotation unless it is empty.
+func generatedSecret(t *testing.T, bookstore *samplev1alpha1.Bookstore, generation string) *corev1.Secret {
+	secret := corev1ac.Secret("test-credentials", bookstore.Namespace).
+		WithLabels(bookstore.GetSelectorLabels()).
+		WithOwnerReferences(controllerRef(bookstore)).
+		WithType(corev1.SecretTypeOpaque).
+		WithData(map[string][]byte{
+			"ADMIN_USERNAME": []byte("admin-0"),
+			"ADMIN_PASSWORD": []byte("password-0"),
+			"JWT_SECRET":     []byte("secret-0"),
+		})
+	if generation != "" {
+		secret.WithAnnotations(map[string]string{samplev1alpha1.RegenerateSecretAnnotation: generation})
+	}
+	return fixture.Apply(t, nil, FieldManager, secret).(*corev1.Secret)
+}
+
+// TestRegeneratesSecret checks that changing the RegenerateSecretAnnotation
+// of a Bookstore with a generated Secret replaces every credential, and
+// rolls the pods onto them.
+func TestRegeneratesSecret(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	bookstore.Spec.GenerateSecret = true
+	bookstore.Spec.SecretRef = nil
+	bookstore.Annotations = map[string]string{samplev1alpha1.RegenerateSecretAnnotation: "1"}
+	secret := generatedSecret(t, bookstore, "1")
+	deployment := appliedDeployment(t, bookstore, secret)
+	bookstore.Annotations[samplev1alpha1.RegenerateSecretAnnotation] = "2"
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+	f.DeploymentLister = append(f.DeploymentLister, deployment)
+	f.ServiceLister = append(f.ServiceLister, appliedService(t, bookstore))
+
+	regenerated := func() *corev1.Secret {
+		live, err := f.KubeClient.CoreV1().Secrets(bookstore.Namespace).Get(ctx, "test-credentials", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("error getting secret: %v", err)
+		}
+		return live
+	}
+	f.ExpectKubeApplyFunc(fixture.SecretsResource, bookstore.Namespace, "test-credentials", func([]byte) {
+		live := regenerated()
+		if generation := live.Annotations[samplev1alpha1.RegenerateSecretAnnotation]; generation != "2" {
+			t.Errorf("expected the secret to record generation 2, got %q", generation)
+		}
+		for key, value := range secret.Data {
+			if string(live.Data[key]) == string(value) {
+				t.Errorf("expected %s to be regenerated, still %q", key, value)
+			}
+		}
+	})
+	f.ExpectKubeApplyFunc(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, func(applied []byte) {
+		want, err := json.Marshal(newDeployment(bookstore, podConfigHash(bookstore, regenerated(), nil), true))
+		if err != nil {
+			t.Fatalf("error encoding deployment: %v", err)
+		}
+		if diff := cmp.Diff(string(want), string(applied)); diff != "" {
+			t.Errorf("unexpected deployment (-want +got):\n%s", diff)
+		}
+	})
+	status := inProgressStatus()
+	status.SecretName = "test-credentials"
+	expectStatus(f, bookstore, status)
+	f.ExpectEvent(corev1.EventTypeNormal, SecretRegenerated, `Secret "test-credentials" regenerated with new random credentials`)
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+// TestKeepsGeneratedSecretMissingFromCache checks that a generated Secret
+// the informer cache has not seen yet is read from the API server, instead of
+// being generated again over the existing credentials.
+func TestKeepsGeneratedSecretMissingFromCache(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	bookstore.Spec.GenerateSecret = true
+	bookstore.Spec.SecretRef = nil
+	secret := generatedSecret(t, bookstore, "")
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.DeploymentLister = append(f.DeploymentLister, appliedDeployment(t, bookstore, secret))
+	f.ServiceLister = append(f.ServiceLister, appliedService(t, bookstore))
+	f.Init()
+	if err := f.KubeClient.Tracker().Add(secret); err != nil {
+		t.Fatalf("error adding secret: %v", err)
+	}
+
+	f.ExpectKubeGet(fixture.SecretsResource, bookstore.Namespace, "test-credentials")
+	status := inProgressStatus()
+	status.SecretName = "test-credentials"
+	expectStatus(f, bookstore, status)
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+// TestKeepsSecretWhenRegenerateAnnotationRemoved checks that removing the
+// RegenerateSecretAnnotation does not count as a request for new
+// credentials.
+func TestKeepsSecretWhenRegenerateAnnotationRemoved(t *testing.T) {
+	f := newFixture(t)
+	bookstore := newBookstore("test", ptr.To[int32](1))
+	bookstore.Spec.GenerateSecret = true
+	bookstore.Spec.SecretRef = nil
+	secret := generatedSecret(t, bookstore, "1")
+	_, ctx := ktesting.NewTestContext(t)
+
+	f.BookstoreLister = append(f.BookstoreLister, bookstore)
+	f.SecretLister = append(f.SecretLister, secret)
+	f.DeploymentLister = append(f.DeploymentLister, appliedDeployment(t, bookstore, secret))
+	f.ServiceLister = append(f.ServiceLister, appliedService(t, bookstore))
+
+	f.ExpectKubeApplyFunc(fixture.SecretsResource, bookstore.Namespace, "test-credentials", func([]byte) {
+		live, err := f.KubeClient.CoreV1().Secrets(bookstore.Namespace).Get(ctx, "test-credentials", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("error getting secret: %v", err)
+		}
+		if diff := cmp.Diff(secret.Data, live.Data); diff != "" {
+			t.Errorf("expected the credentials to be kept (-want +got):\n%s", diff)
+		}
+		if generation, ok := live.Annotations[samplev1alpha1.RegenerateSecretAnnotation]; ok {
+			t.Errorf("expected the secret to drop the recorded generation, got %q", generation)
+		}
+	})
+	status := inProgressStatus()
+	status.SecretName = "test-credentials"
+	expectStatus(f, bookstore, status)
+	f.ExpectEvent(corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+
+	f.Run(reconcile(ctx, f, getKey(bookstore, t)))
+}
+
+// TestRequeueClasses checks the class of the error Reconcile returns for
+// each kind of failure of the API server, which decides how the Bookstore
+// is requeued.
+func TestRequeueClasses(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		class      ErrorClass
+		retryAfter time.Duration
+		// reason and message are those of the failure conditions.
+		reason  string
+		message string
+	}{{
+		name:    "transient",
+		err:     apierrors.NewInternalError(fmt.Errorf("etcdserver: request timed out")),
+		class:   ErrorTransient,
+		reason:  "SyncFailed",
+		message: "Internal error occurred: etcdserver: request timed out",
+	}, {
+		name:    "conflict",
+		err:     apierrors.NewConflict(fixture.DeploymentsResource.GroupResource(), "test-deployment", fmt.Errorf("the object has been modified")),
+		class:   ErrorConflict,
+		reason:  "SyncFailed",
+		message: `Operation cannot be fulfilled on deployments.apps "test-deployment": the object has been modified`,
+	}, {
+		name:       "rate limited",
+		err:        apierrors.NewTooManyRequests("too many requests, retry later", 3),
+		class:      ErrorRateLimited,
+		retryAfter: 3 * time.Second,
+		reason:     "SyncFailed",
+		message:    "too many requests, retry later",
+	}, {
+		name: "terminal",
+		err: apierrors.NewInvalid(appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind(), "test-deployment", field.ErrorList{
+			field.Invalid(field.NewPath("spec", "template", "spec", "containers").Index(0).Child("image"), "", "must not be empty"),
+		}),
+		class:   ErrorTerminal,
+		reason:  "InvalidSpec",
+		message: `Deployment.apps "test-deployment" is invalid: spec.template.spec.containers[0].image: Invalid value: "": must not be empty`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			bookstore := newBookstore("test", ptr.To[int32](1))
+			secret := newSecret(bookstore)
+			_, ctx := ktesting.NewTestContext(t)
+
+			f.BookstoreLister = append(f.BookstoreLister, bookstore)
+			f.SecretLister = append(f.SecretLister, secret)
+			f.Init()
+			f.KubeClient.PrependReactor("patch", "deployments", func(core.Action) (bool, runtime.Object, error) {
+				return true, nil, tt.err
+			})
+
+			failed := condition("Failed", metav1.ConditionFalse, "Retrying", "The last sync failed and is retried: "+tt.message)
+			if tt.class == ErrorTerminal {
+				failed = condition("Failed", metav1.ConditionTrue, tt.reason, tt.message)
+			}
+			f.ExpectKubeApply(fixture.DeploymentsResource, bookstore.Namespace, bookstore.Spec.DeploymentName, newDeployment(bookstore, podConfigHash(bookstore, secret, nil), true))
+			expectStatus(f, bookstore, samplev1alpha1.BookstoreStatus{
+				ObservedGeneration: 1,
+				Selector:           testSelector,
+				SecretName:         "env-secrets",
+				Conditions: []metav1.Condition{
+					condition("ResourceConflict", metav1.ConditionFalse, "NoConflict", noConflictMessage),
+					condition("SecretMissing", metav1.ConditionFalse, "SecretFound", secretFoundMessage),
+					failed,
+					condition("Degraded", metav1.ConditionTrue, tt.reason, tt.message),
+					condition("Progressing", metav1.ConditionFalse, tt.reason, tt.message),
+					condition("Ready", metav1.ConditionFalse, tt.reason, tt.message),
+				},
+			})
+
+			err := f.RunExpectError(reconcile(ctx, f, getKey(bookstore, t)))
+			if class := ClassifyError(err); class != tt.class {
+				t.Errorf("expected error class %d, got %d for %v", tt.class, class, err)
+			}
+			if retryAfter, _ := RetryAfter(err); retryAfter != tt.retryAfter {
+				t.Errorf("expected a retry after %v, got %v", tt.retryAfter, retryAfter)
+			}
+		})
+	}
+}
+
+// TestPreStopHookFollowsServerVersion checks that the preStop hook only uses
+// the sleep action on API servers that accept it, and runs sleep otherwise.
+func TestPreStopHookFollowsServerVersion(t *testing.T) {
+	tests := []struct {
+		gitVersion string
+		want       *corev1ac.LifecycleHandlerApplyConfiguration
+	}{{
+		gitVersion: "v1.29.4",
+		want:       corev1ac.LifecycleHandler().WithExec(corev1ac.ExecAction().WithCommand("sleep", "5")),
+	}, {
+		gitVersion: "v1.30.0",
+		want:       corev1ac.LifecycleHandler().WithSleep(corev1ac.SleepAction().WithSeconds(5)),
+	}, {
+		gitVersion: "v1.31.2-gke.1200000",
+		want:       corev1ac.LifecycleHandler().WithSleep(corev1ac.SleepAction().WithSeconds(5)),
+	}}
+	for _, test := range tests {
+		t.Run(test.gitVersion, func(t *testing.T) {
+			discovery := k8sfake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+			discovery.FakedServerVersion = &version.Info{GitVersion: test.gitVersion}
+			sleepAction, err := SupportsSleepAction(discovery)
+			if err != nil {
+				t.Fatalf("error checking the server version: %v", err)
+			}
+
+			bookstore := newBookstore("test", ptr.To[int32](1))
+			bookstore.Spec.Workload.PreStopSleepSeconds = ptr.To[int64](5)
+			container := newDeployment(bookstore, "", sleepAction).Spec.Template.Spec.Containers[0]
+			if diff := cmp.Diff(test.want, container.Lifecycle.PreStop); diff != "" {
+				t.Errorf("unexpected preStop hook (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bookstore
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	samplev1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+)
+
+// These are the reasons used for the conditions in BookstoreStatus.
+const (
+	ReasonInvalidSpec              = "InvalidSpec"
+	ReasonSyncFailed               = "SyncFailed"
+	ReasonNoConflict               = "NoConflict"
+	ReasonRolloutInProgress        = "RolloutInProgress"
+	ReasonRolloutComplete          = "RolloutComplete"
+	ReasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+	ReasonSecretNotFound           = "SecretNotFound"
+	ReasonSecretKeyNotFound        = "SecretKeyNotFound"
+	ReasonSecretFound              = "SecretFound"
+	ReasonRetrying                 = "Retrying"
+)
+
+// invalidSpecError is returned when a Bookstore spec cannot be acted upon
+// until the user changes it.
+type invalidSpecError struct {
+	message string
+}
+
+func (e *invalidSpecError) Error() string {
+	return e.message
+}
+
+// isInvalidSpec reports whether err was caused by an invalid Bookstore spec.
+func isInvalidSpec(err error) bool {
+	var invalid *invalidSpecError
+	return errors.As(err, &invalid)
+}
+
+// resourceConflictError is returned when a child resource named in the
+// Bookstore spec exists but is not controlled by the Bookstore.
+type resourceConflictError struct {
+	name string
+	// detail explains why the resource could not be adopted, if adoption
+	// was asked for.
+	detail string
+}
+
+func (e *resourceConflictError) Error() string {
+	if e.detail != "" {
+		return fmt.Sprintf(MessageResourceExists, e.name) + ": " + e.detail
+	}
+	return fmt.Sprintf(MessageResourceExists, e.name)
+}
+
+// secretMissingError is returned when the credentials Secret of a Bookstore
+// does not exist, or exists but lacks some of the keys the Bookstore reads.
+type secretMissingError struct {
+	name string
+	// keys lists the missing keys. It is empty if the Secret itself is
+	// missing.
+	keys []string
+}
+
+func (e *secretMissingError) Error() string {
+	if len(e.keys) == 0 {
+		return fmt.Sprintf("secret %q not found", e.name)
+	}
+	return fmt.Sprintf("secret %q has no key %s", e.name, strings.Join(quoteAll(e.keys), ", "))
+}
+
+// reason returns the reason of the SecretMissing condition for e.
+func (e *secretMissingError) reason() string {
+	if len(e.keys) == 0 {
+		return ReasonSecretNotFound
+	}
+	return ReasonSecretKeyNotFound
+}
+
+// isSecretMissing reports whether err was caused by a missing credentials
+// Secret or Secret key.
+func isSecretMissing(err error) bool {
+	var missing *secretMissingError
+	return errors.As(err, &missing)
+}
+
+// quoteAll returns a copy of values with every value quoted.
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return quoted
+}
+
+// setStatusConditions records the outcome of a sync in status. deployment is
+// the Deployment observed during the sync and may be nil if the sync failed
+// before it was known. syncErr is the error the sync failed with, if any.
+func setStatusConditions(status *samplev1alpha1.BookstoreStatus, generation int64, deployment *appsv1.Deployment, syncErr error) {
+	set := func(conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             conditionStatus,
+			ObservedGeneration: generation,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+
+	var conflict *resourceConflictError
+	var fieldConflict *fieldConflictError
+	var invalid *invalidSpecError
+	var missing *secretMissingError
+	var failure string
+	if syncErr != nil {
+		failure = syncErr.Error()
+	}
+	failureReason := ReasonSyncFailed
+	switch {
+	case errors.As(syncErr, &conflict):
+		failureReason = ErrResourceExists
+	case errors.As(syncErr, &fieldConflict):
+		failureReason = FieldConflict
+	case errors.As(syncErr, &invalid), apierrors.IsInvalid(syncErr):
+		failureReason = ReasonInvalidSpec
+	case errors.As(syncErr, &missing):
+		failureReason = missing.reason()
+	}
+
+	if conflict != nil || fieldConflict != nil {
+		set(samplev1alpha1.BookstoreResourceConflict, metav1.ConditionTrue, failureReason, failure)
+	} else {
+		set(samplev1alpha1.BookstoreResourceConflict, metav1.ConditionFalse, ReasonNoConflict, "All child resources are managed by this Bookstore")
+	}
+
+	// The Secret is checked before anything else is synced, so any other
+	// outcome means it was found.
+	if missing != nil {
+		set(samplev1alpha1.BookstoreSecretMissing, metav1.ConditionTrue, failureReason, failure)
+	} else {
+		set(samplev1alpha1.BookstoreSecretMissing, metav1.ConditionFalse, ReasonSecretFound, "The credentials Secret holds every key read by the Bookstore")
+	}
+
+	switch {
+	case isTerminal(syncErr):
+		set(samplev1alpha1.BookstoreFailed, metav1.ConditionTrue, failureReason, failure)
+	case syncErr != nil:
+		set(samplev1alpha1.BookstoreFailed, metav1.ConditionFalse, ReasonRetrying, "The last sync failed and is retried: "+failure)
+	default:
+		set(samplev1alpha1.BookstoreFailed, metav1.ConditionFalse, SuccessSynced, MessageResourceSynced)
+	}
+
+	complete, stuck, progress := deploymentRolloutStatus(deployment)
+	switch {
+	case syncErr != nil:
+		set(samplev1alpha1.BookstoreDegraded, metav1.ConditionTrue, failureReason, failure)
+	case stuck:
+		set(samplev1alpha1.BookstoreDegraded, metav1.ConditionTrue, ReasonProgressDeadlineExceeded, progress)
+	default:
+		set(samplev1alpha1.BookstoreDegraded, metav1.ConditionFalse, SuccessSynced, MessageResourceSynced)
+	}
+
+	switch {
+	case deployment == nil:
+		set(samplev1alpha1.BookstoreProgressing, metav1.ConditionFalse, failureReason, failure)
+	case stuck:
+		set(samplev1alpha1.BookstoreProgressing, metav1.ConditionFalse, ReasonProgressDeadlineExceeded, progress)
+	case complete:
+		set(samplev1alpha1.BookstoreProgressing, metav1.ConditionFalse, ReasonRolloutComplete, progress)
+	default:
+		set(samplev1alpha1.BookstoreProgressing, metav1.ConditionTrue, ReasonRolloutInProgress, progress)
+	}
+
+	switch {
+	case syncErr != nil:
+		set(samplev1alpha1.BookstoreReady, metav1.ConditionFalse, failureReason, failure)
+	case complete:
+		set(samplev1alpha1.BookstoreReady, metav1.ConditionTrue, ReasonRolloutComplete, progress)
+	case stuck:
+		set(samplev1alpha1.BookstoreReady, metav1.ConditionFalse, ReasonProgressDeadlineExceeded, progress)
+	default:
+		set(samplev1alpha1.BookstoreReady, metav1.ConditionFalse, ReasonRolloutInProgress, progress)
+	}
+}
+
+// deploymentRolloutStatus reports whether the rollout of deployment is
+// complete or has exceeded its progress deadline, along with a human readable
+// summary of its replicas.
+func deploymentRolloutStatus(deployment *appsv1.Deployment) (complete, stuck bool, progress string) {
+	if deployment == nil {
+		return false, false, ""
+	}
+
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	progress = fmt.Sprintf("%d of %d replicas available", status.AvailableReplicas, desired)
+
+	for _, condition := range status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse && condition.Reason == ReasonProgressDeadlineExceeded {
+			stuck = true
+		}
+	}
+
+	complete = status.ObservedGeneration >= deployment.Generation &&
+		status.UpdatedReplicas == desired &&
+		status.Replicas == desired &&
+		status.AvailableReplicas == desired
+	return complete, stuck && !complete, progress
+}
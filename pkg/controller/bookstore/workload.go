@@ -0,0 +1,110 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bookstore
+
+import (
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/version"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/discovery"
+
+	samplev1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+)
+
+// sleepActionVersion is the first Kubernetes version that enables the sleep
+// action of lifecycle hooks by default.
+var sleepActionVersion = version.MajorMinor(1, 30)
+
+// SupportsSleepAction reports whether the API server behind client accepts
+// the sleep action in lifecycle hooks, going by its version.
+func SupportsSleepAction(client discovery.ServerVersionInterface) (bool, error) {
+	info, err := client.ServerVersion()
+	if err != nil {
+		return false, err
+	}
+	serverVersion, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return false, err
+	}
+	return serverVersion.AtLeast(sleepActionVersion), nil
+}
+
+// withWorkload sets the resources, probes and preStop hook of the workload
+// section of the spec on the bookstore container. sleepAction tells whether
+// the preStop hook may use the sleep action.
+func withWorkload(container *corev1ac.ContainerApplyConfiguration, bookstore *samplev1alpha1.Bookstore, sleepAction bool) *corev1ac.ContainerApplyConfiguration {
+	workload := bookstore.Spec.Workload
+	port := bookstore.Spec.ContainerPort
+
+	if resources := workload.Resources; resources != nil {
+		requirements := corev1ac.ResourceRequirements()
+		if len(resources.Requests) > 0 {
+			requirements.WithRequests(resources.Requests)
+		}
+		if len(resources.Limits) > 0 {
+			requirements.WithLimits(resources.Limits)
+		}
+		container.WithResources(requirements)
+	}
+
+	// The startup probe gives the API up to a minute to start listening
+	// before the liveness probe may restart it.
+	container.
+		WithStartupProbe(containerProbe(workload.Probes.StartupPath, port).
+			WithPeriodSeconds(2).
+			WithFailureThreshold(30)).
+		WithReadinessProbe(containerProbe(workload.Probes.ReadinessPath, port).
+			WithPeriodSeconds(5)).
+		WithLivenessProbe(containerProbe(workload.Probes.LivenessPath, port).
+			WithPeriodSeconds(10).
+			WithFailureThreshold(3))
+
+	if sleep := workload.PreStopSleepSeconds; sleep != nil && *sleep > 0 {
+		container.WithLifecycle(corev1ac.Lifecycle().
+			WithPreStop(preStopSleep(*sleep, sleepAction)))
+	}
+	return container
+}
+
+// preStopSleep returns a hook that sleeps for the given number of seconds.
+// The sleep action needs no sleep binary in the image, but older API
+// servers reject it, so without sleepAction the hook runs sleep instead.
+func preStopSleep(seconds int64, sleepAction bool) *corev1ac.LifecycleHandlerApplyConfiguration {
+	if sleepAction {
+		return corev1ac.LifecycleHandler().
+			WithSleep(corev1ac.SleepAction().WithSeconds(seconds))
+	}
+	return corev1ac.LifecycleHandler().
+		WithExec(corev1ac.ExecAction().
+			WithCommand("sleep", strconv.FormatInt(seconds, 10)))
+}
+
+// containerProbe returns a probe of port: an HTTP GET of path, or a TCP
+// connection if path is empty.
+func containerProbe(path string, port int32) *corev1ac.ProbeApplyConfiguration {
+	if path == "" {
+		return corev1ac.Probe().
+			WithTCPSocket(corev1ac.TCPSocketAction().
+				WithPort(intstr.FromInt32(port)))
+	}
+	return corev1ac.Probe().
+		WithHTTPGet(corev1ac.HTTPGetAction().
+			WithPath(path).
+			WithPort(intstr.FromInt32(port)))
+}
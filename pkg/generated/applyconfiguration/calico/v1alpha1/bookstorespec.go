@@ -0,0 +1,206 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// BookstoreSpecApplyConfiguration represents an declarative configuration of the BookstoreSpec type for use
+// with apply.
+type BookstoreSpecApplyConfiguration struct {
+	EnvAdminUsername    *string                         `json:"envAdminUsername,omitempty"`
+	EnvAdminPassword    *string                         `json:"envAdminPassword,omitempty"`
+	EnvJWTSECRET        *string                         `json:"envJWTSECRET,omitempty"`
+	DeploymentImageName *string                         `json:"deploymentImageName,omitempty"`
+	DeploymentImageTag  *string                         `json:"deploymentImageTag,omitempty"`
+	ImagePullPolicy     *string                         `json:"imagePullPolicy,omitempty"`
+	DeploymentName      *string                         `json:"deploymentName,omitempty"`
+	Replicas            *int32                          `json:"replicas,omitempty"`
+	ServiceName         *string                         `json:"serviceName,omitempty"`
+	ServiceType         *string                         `json:"serviceType,omitempty"`
+	ContainerPort       *int32                          `json:"containerPort,omitempty"`
+	NodePort            *int32                          `json:"nodePort,omitempty"`
+	TargetPort          *int32                          `json:"targetPort,omitempty"`
+	SecretRef           *v1.LocalObjectReference        `json:"secretRef,omitempty"`
+	GenerateSecret      *bool                           `json:"generateSecret,omitempty"`
+	ConfigMapRef        *v1.LocalObjectReference        `json:"configMapRef,omitempty"`
+	DeletionPolicy      *string                         `json:"deletionPolicy,omitempty"`
+	Workload            *WorkloadSpecApplyConfiguration `json:"workload,omitempty"`
+	PodTemplate         *runtime.RawExtension           `json:"podTemplate,omitempty"`
+}
+
+// BookstoreSpecApplyConfiguration constructs an declarative configuration of the BookstoreSpec type for use with
+// apply.
+func BookstoreSpec() *BookstoreSpecApplyConfiguration {
+	return &BookstoreSpecApplyConfiguration{}
+}
+
+// WithEnvAdminUsername sets the EnvAdminUsername field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the EnvAdminUsername field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithEnvAdminUsername(value string) *BookstoreSpecApplyConfiguration {
+	b.EnvAdminUsername = &value
+	return b
+}
+
+// WithEnvAdminPassword sets the EnvAdminPassword field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the EnvAdminPassword field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithEnvAdminPassword(value string) *BookstoreSpecApplyConfiguration {
+	b.EnvAdminPassword = &value
+	return b
+}
+
+// WithEnvJWTSECRET sets the EnvJWTSECRET field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the EnvJWTSECRET field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithEnvJWTSECRET(value string) *BookstoreSpecApplyConfiguration {
+	b.EnvJWTSECRET = &value
+	return b
+}
+
+// WithDeploymentImageName sets the DeploymentImageName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DeploymentImageName field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithDeploymentImageName(value string) *BookstoreSpecApplyConfiguration {
+	b.DeploymentImageName = &value
+	return b
+}
+
+// WithDeploymentImageTag sets the DeploymentImageTag field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DeploymentImageTag field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithDeploymentImageTag(value string) *BookstoreSpecApplyConfiguration {
+	b.DeploymentImageTag = &value
+	return b
+}
+
+// WithImagePullPolicy sets the ImagePullPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ImagePullPolicy field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithImagePullPolicy(value string) *BookstoreSpecApplyConfiguration {
+	b.ImagePullPolicy = &value
+	return b
+}
+
+// WithDeploymentName sets the DeploymentName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DeploymentName field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithDeploymentName(value string) *BookstoreSpecApplyConfiguration {
+	b.DeploymentName = &value
+	return b
+}
+
+// WithReplicas sets the Replicas field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Replicas field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithReplicas(value int32) *BookstoreSpecApplyConfiguration {
+	b.Replicas = &value
+	return b
+}
+
+// WithServiceName sets the ServiceName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ServiceName field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithServiceName(value string) *BookstoreSpecApplyConfiguration {
+	b.ServiceName = &value
+	return b
+}
+
+// WithServiceType sets the ServiceType field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ServiceType field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithServiceType(value string) *BookstoreSpecApplyConfiguration {
+	b.ServiceType = &value
+	return b
+}
+
+// WithContainerPort sets the ContainerPort field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ContainerPort field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithContainerPort(value int32) *BookstoreSpecApplyConfiguration {
+	b.ContainerPort = &value
+	return b
+}
+
+// WithNodePort sets the NodePort field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the NodePort field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithNodePort(value int32) *BookstoreSpecApplyConfiguration {
+	b.NodePort = &value
+	return b
+}
+
+// WithTargetPort sets the TargetPort field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TargetPort field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithTargetPort(value int32) *BookstoreSpecApplyConfiguration {
+	b.TargetPort = &value
+	return b
+}
+
+// WithSecretRef sets the SecretRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SecretRef field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithSecretRef(value v1.LocalObjectReference) *BookstoreSpecApplyConfiguration {
+	b.SecretRef = &value
+	return b
+}
+
+// WithGenerateSecret sets the GenerateSecret field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the GenerateSecret field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithGenerateSecret(value bool) *BookstoreSpecApplyConfiguration {
+	b.GenerateSecret = &value
+	return b
+}
+
+// WithConfigMapRef sets the ConfigMapRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ConfigMapRef field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithConfigMapRef(value v1.LocalObjectReference) *BookstoreSpecApplyConfiguration {
+	b.ConfigMapRef = &value
+	return b
+}
+
+// WithDeletionPolicy sets the DeletionPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DeletionPolicy field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithDeletionPolicy(value string) *BookstoreSpecApplyConfiguration {
+	b.DeletionPolicy = &value
+	return b
+}
+
+// WithWorkload sets the Workload field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Workload field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithWorkload(value *WorkloadSpecApplyConfiguration) *BookstoreSpecApplyConfiguration {
+	b.Workload = value
+	return b
+}
+
+// WithPodTemplate sets the PodTemplate field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PodTemplate field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithPodTemplate(value runtime.RawExtension) *BookstoreSpecApplyConfiguration {
+	b.PodTemplate = &value
+	return b
+}
@@ -0,0 +1,57 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ProbesSpecApplyConfiguration represents an declarative configuration of the ProbesSpec type for use
+// with apply.
+type ProbesSpecApplyConfiguration struct {
+	StartupPath   *string `json:"startupPath,omitempty"`
+	ReadinessPath *string `json:"readinessPath,omitempty"`
+	LivenessPath  *string `json:"livenessPath,omitempty"`
+}
+
+// ProbesSpecApplyConfiguration constructs an declarative configuration of the ProbesSpec type for use with
+// apply.
+func ProbesSpec() *ProbesSpecApplyConfiguration {
+	return &ProbesSpecApplyConfiguration{}
+}
+
+// WithStartupPath sets the StartupPath field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the StartupPath field is set to the value of the last call.
+func (b *ProbesSpecApplyConfiguration) WithStartupPath(value string) *ProbesSpecApplyConfiguration {
+	b.StartupPath = &value
+	return b
+}
+
+// WithReadinessPath sets the ReadinessPath field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ReadinessPath field is set to the value of the last call.
+func (b *ProbesSpecApplyConfiguration) WithReadinessPath(value string) *ProbesSpecApplyConfiguration {
+	b.ReadinessPath = &value
+	return b
+}
+
+// WithLivenessPath sets the LivenessPath field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LivenessPath field is set to the value of the last call.
+func (b *ProbesSpecApplyConfiguration) WithLivenessPath(value string) *ProbesSpecApplyConfiguration {
+	b.LivenessPath = &value
+	return b
+}
@@ -0,0 +1,70 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// WorkloadSpecApplyConfiguration represents an declarative configuration of the WorkloadSpec type for use
+// with apply.
+type WorkloadSpecApplyConfiguration struct {
+	Resources                     *v1.ResourceRequirements      `json:"resources,omitempty"`
+	Probes                        *ProbesSpecApplyConfiguration `json:"probes,omitempty"`
+	TerminationGracePeriodSeconds *int64                        `json:"terminationGracePeriodSeconds,omitempty"`
+	PreStopSleepSeconds           *int64                        `json:"preStopSleepSeconds,omitempty"`
+}
+
+// WorkloadSpecApplyConfiguration constructs an declarative configuration of the WorkloadSpec type for use with
+// apply.
+func WorkloadSpec() *WorkloadSpecApplyConfiguration {
+	return &WorkloadSpecApplyConfiguration{}
+}
+
+// WithResources sets the Resources field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Resources field is set to the value of the last call.
+func (b *WorkloadSpecApplyConfiguration) WithResources(value v1.ResourceRequirements) *WorkloadSpecApplyConfiguration {
+	b.Resources = &value
+	return b
+}
+
+// WithProbes sets the Probes field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Probes field is set to the value of the last call.
+func (b *WorkloadSpecApplyConfiguration) WithProbes(value *ProbesSpecApplyConfiguration) *WorkloadSpecApplyConfiguration {
+	b.Probes = value
+	return b
+}
+
+// WithTerminationGracePeriodSeconds sets the TerminationGracePeriodSeconds field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TerminationGracePeriodSeconds field is set to the value of the last call.
+func (b *WorkloadSpecApplyConfiguration) WithTerminationGracePeriodSeconds(value int64) *WorkloadSpecApplyConfiguration {
+	b.TerminationGracePeriodSeconds = &value
+	return b
+}
+
+// WithPreStopSleepSeconds sets the PreStopSleepSeconds field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PreStopSleepSeconds field is set to the value of the last call.
+func (b *WorkloadSpecApplyConfiguration) WithPreStopSleepSeconds(value int64) *WorkloadSpecApplyConfiguration {
+	b.PreStopSleepSeconds = &value
+	return b
+}
@@ -0,0 +1,79 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	calicov1beta1 "k8s.io/sample-controller/pkg/apis/calico/v1beta1"
+)
+
+// BookstoreSpecApplyConfiguration represents an declarative configuration of the BookstoreSpec type for use
+// with apply.
+type BookstoreSpecApplyConfiguration struct {
+	Image          *ImageSpecApplyConfiguration       `json:"image,omitempty"`
+	Service        *ServiceSpecApplyConfiguration     `json:"service,omitempty"`
+	Credentials    *CredentialsSpecApplyConfiguration `json:"credentials,omitempty"`
+	Workload       *WorkloadSpecApplyConfiguration    `json:"workload,omitempty"`
+	DeletionPolicy *calicov1beta1.DeletionPolicy      `json:"deletionPolicy,omitempty"`
+}
+
+// BookstoreSpecApplyConfiguration constructs an declarative configuration of the BookstoreSpec type for use with
+// apply.
+func BookstoreSpec() *BookstoreSpecApplyConfiguration {
+	return &BookstoreSpecApplyConfiguration{}
+}
+
+// WithImage sets the Image field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Image field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithImage(value *ImageSpecApplyConfiguration) *BookstoreSpecApplyConfiguration {
+	b.Image = value
+	return b
+}
+
+// WithService sets the Service field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Service field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithService(value *ServiceSpecApplyConfiguration) *BookstoreSpecApplyConfiguration {
+	b.Service = value
+	return b
+}
+
+// WithCredentials sets the Credentials field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Credentials field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithCredentials(value *CredentialsSpecApplyConfiguration) *BookstoreSpecApplyConfiguration {
+	b.Credentials = value
+	return b
+}
+
+// WithWorkload sets the Workload field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Workload field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithWorkload(value *WorkloadSpecApplyConfiguration) *BookstoreSpecApplyConfiguration {
+	b.Workload = value
+	return b
+}
+
+// WithDeletionPolicy sets the DeletionPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DeletionPolicy field is set to the value of the last call.
+func (b *BookstoreSpecApplyConfiguration) WithDeletionPolicy(value calicov1beta1.DeletionPolicy) *BookstoreSpecApplyConfiguration {
+	b.DeletionPolicy = &value
+	return b
+}
@@ -0,0 +1,93 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1 "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+// BookstoreStatusApplyConfiguration represents an declarative configuration of the BookstoreStatus type for use
+// with apply.
+type BookstoreStatusApplyConfiguration struct {
+	ObservedGeneration *int64                           `json:"observedGeneration,omitempty"`
+	Replicas           *int32                           `json:"replicas,omitempty"`
+	AvailableReplicas  *int32                           `json:"availableReplicas,omitempty"`
+	Selector           *string                          `json:"selector,omitempty"`
+	SecretName         *string                          `json:"secretName,omitempty"`
+	Conditions         []v1.ConditionApplyConfiguration `json:"conditions,omitempty"`
+}
+
+// BookstoreStatusApplyConfiguration constructs an declarative configuration of the BookstoreStatus type for use with
+// apply.
+func BookstoreStatus() *BookstoreStatusApplyConfiguration {
+	return &BookstoreStatusApplyConfiguration{}
+}
+
+// WithObservedGeneration sets the ObservedGeneration field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ObservedGeneration field is set to the value of the last call.
+func (b *BookstoreStatusApplyConfiguration) WithObservedGeneration(value int64) *BookstoreStatusApplyConfiguration {
+	b.ObservedGeneration = &value
+	return b
+}
+
+// WithReplicas sets the Replicas field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Replicas field is set to the value of the last call.
+func (b *BookstoreStatusApplyConfiguration) WithReplicas(value int32) *BookstoreStatusApplyConfiguration {
+	b.Replicas = &value
+	return b
+}
+
+// WithAvailableReplicas sets the AvailableReplicas field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AvailableReplicas field is set to the value of the last call.
+func (b *BookstoreStatusApplyConfiguration) WithAvailableReplicas(value int32) *BookstoreStatusApplyConfiguration {
+	b.AvailableReplicas = &value
+	return b
+}
+
+// WithSelector sets the Selector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Selector field is set to the value of the last call.
+func (b *BookstoreStatusApplyConfiguration) WithSelector(value string) *BookstoreStatusApplyConfiguration {
+	b.Selector = &value
+	return b
+}
+
+// WithSecretName sets the SecretName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SecretName field is set to the value of the last call.
+func (b *BookstoreStatusApplyConfiguration) WithSecretName(value string) *BookstoreStatusApplyConfiguration {
+	b.SecretName = &value
+	return b
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *BookstoreStatusApplyConfiguration) WithConditions(values ...*v1.ConditionApplyConfiguration) *BookstoreStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithConditions")
+		}
+		b.Conditions = append(b.Conditions, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,79 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// CredentialsSpecApplyConfiguration represents an declarative configuration of the CredentialsSpec type for use
+// with apply.
+type CredentialsSpecApplyConfiguration struct {
+	AdminUsernameKey *string                  `json:"adminUsernameKey,omitempty"`
+	AdminPasswordKey *string                  `json:"adminPasswordKey,omitempty"`
+	JWTSecretKey     *string                  `json:"jwtSecretKey,omitempty"`
+	SecretRef        *v1.LocalObjectReference `json:"secretRef,omitempty"`
+	Generate         *bool                    `json:"generate,omitempty"`
+}
+
+// CredentialsSpecApplyConfiguration constructs an declarative configuration of the CredentialsSpec type for use with
+// apply.
+func CredentialsSpec() *CredentialsSpecApplyConfiguration {
+	return &CredentialsSpecApplyConfiguration{}
+}
+
+// WithAdminUsernameKey sets the AdminUsernameKey field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AdminUsernameKey field is set to the value of the last call.
+func (b *CredentialsSpecApplyConfiguration) WithAdminUsernameKey(value string) *CredentialsSpecApplyConfiguration {
+	b.AdminUsernameKey = &value
+	return b
+}
+
+// WithAdminPasswordKey sets the AdminPasswordKey field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AdminPasswordKey field is set to the value of the last call.
+func (b *CredentialsSpecApplyConfiguration) WithAdminPasswordKey(value string) *CredentialsSpecApplyConfiguration {
+	b.AdminPasswordKey = &value
+	return b
+}
+
+// WithJWTSecretKey sets the JWTSecretKey field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the JWTSecretKey field is set to the value of the last call.
+func (b *CredentialsSpecApplyConfiguration) WithJWTSecretKey(value string) *CredentialsSpecApplyConfiguration {
+	b.JWTSecretKey = &value
+	return b
+}
+
+// WithSecretRef sets the SecretRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SecretRef field is set to the value of the last call.
+func (b *CredentialsSpecApplyConfiguration) WithSecretRef(value v1.LocalObjectReference) *CredentialsSpecApplyConfiguration {
+	b.SecretRef = &value
+	return b
+}
+
+// WithGenerate sets the Generate field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Generate field is set to the value of the last call.
+func (b *CredentialsSpecApplyConfiguration) WithGenerate(value bool) *CredentialsSpecApplyConfiguration {
+	b.Generate = &value
+	return b
+}
@@ -0,0 +1,61 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// ImageSpecApplyConfiguration represents an declarative configuration of the ImageSpec type for use
+// with apply.
+type ImageSpecApplyConfiguration struct {
+	Name       *string        `json:"name,omitempty"`
+	Tag        *string        `json:"tag,omitempty"`
+	PullPolicy *v1.PullPolicy `json:"pullPolicy,omitempty"`
+}
+
+// ImageSpecApplyConfiguration constructs an declarative configuration of the ImageSpec type for use with
+// apply.
+func ImageSpec() *ImageSpecApplyConfiguration {
+	return &ImageSpecApplyConfiguration{}
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *ImageSpecApplyConfiguration) WithName(value string) *ImageSpecApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithTag sets the Tag field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Tag field is set to the value of the last call.
+func (b *ImageSpecApplyConfiguration) WithTag(value string) *ImageSpecApplyConfiguration {
+	b.Tag = &value
+	return b
+}
+
+// WithPullPolicy sets the PullPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PullPolicy field is set to the value of the last call.
+func (b *ImageSpecApplyConfiguration) WithPullPolicy(value v1.PullPolicy) *ImageSpecApplyConfiguration {
+	b.PullPolicy = &value
+	return b
+}
@@ -0,0 +1,572 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package internal
+
+import (
+	"fmt"
+	"sync"
+
+	typed "sigs.k8s.io/structured-merge-diff/v4/typed"
+)
+
+func Parser() *typed.Parser {
+	parserOnce.Do(func() {
+		var err error
+		parser, err = typed.NewParser(schemaYAML)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to parse schema: %v", err))
+		}
+	})
+	return parser
+}
+
+var parserOnce sync.Once
+var parser *typed.Parser
+var schemaYAML = typed.YAMLObject(`types:
+- name: io.k8s.api.core.v1.LocalObjectReference
+  map:
+    fields:
+    - name: name
+      type:
+        scalar: string
+      default: ""
+    elementRelationship: atomic
+- name: io.k8s.api.core.v1.ResourceClaim
+  map:
+    fields:
+    - name: name
+      type:
+        scalar: string
+      default: ""
+- name: io.k8s.api.core.v1.ResourceRequirements
+  map:
+    fields:
+    - name: claims
+      type:
+        list:
+          elementType:
+            namedType: io.k8s.api.core.v1.ResourceClaim
+          elementRelationship: associative
+          keys:
+          - name
+    - name: limits
+      type:
+        map:
+          elementType:
+            namedType: io.k8s.apimachinery.pkg.api.resource.Quantity
+    - name: requests
+      type:
+        map:
+          elementType:
+            namedType: io.k8s.apimachinery.pkg.api.resource.Quantity
+- name: io.k8s.apimachinery.pkg.api.resource.Quantity
+  scalar: untyped
+- name: io.k8s.apimachinery.pkg.apis.meta.v1.Condition
+  map:
+    fields:
+    - name: lastTransitionTime
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+    - name: message
+      type:
+        scalar: string
+      default: ""
+    - name: observedGeneration
+      type:
+        scalar: numeric
+    - name: reason
+      type:
+        scalar: string
+      default: ""
+    - name: status
+      type:
+        scalar: string
+      default: ""
+    - name: type
+      type:
+        scalar: string
+      default: ""
+- name: io.k8s.apimachinery.pkg.apis.meta.v1.FieldsV1
+  map:
+    elementType:
+      scalar: untyped
+      list:
+        elementType:
+          namedType: __untyped_atomic_
+        elementRelationship: atomic
+      map:
+        elementType:
+          namedType: __untyped_deduced_
+        elementRelationship: separable
+- name: io.k8s.apimachinery.pkg.apis.meta.v1.ManagedFieldsEntry
+  map:
+    fields:
+    - name: apiVersion
+      type:
+        scalar: string
+    - name: fieldsType
+      type:
+        scalar: string
+    - name: fieldsV1
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.FieldsV1
+    - name: manager
+      type:
+        scalar: string
+    - name: operation
+      type:
+        scalar: string
+    - name: subresource
+      type:
+        scalar: string
+    - name: time
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+- name: io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta
+  map:
+    fields:
+    - name: annotations
+      type:
+        map:
+          elementType:
+            scalar: string
+    - name: creationTimestamp
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+    - name: deletionGracePeriodSeconds
+      type:
+        scalar: numeric
+    - name: deletionTimestamp
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+    - name: finalizers
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: associative
+    - name: generateName
+      type:
+        scalar: string
+    - name: generation
+      type:
+        scalar: numeric
+    - name: labels
+      type:
+        map:
+          elementType:
+            scalar: string
+    - name: managedFields
+      type:
+        list:
+          elementType:
+            namedType: io.k8s.apimachinery.pkg.apis.meta.v1.ManagedFieldsEntry
+          elementRelationship: atomic
+    - name: name
+      type:
+        scalar: string
+    - name: namespace
+      type:
+        scalar: string
+    - name: ownerReferences
+      type:
+        list:
+          elementType:
+            namedType: io.k8s.apimachinery.pkg.apis.meta.v1.OwnerReference
+          elementRelationship: associative
+          keys:
+          - uid
+    - name: resourceVersion
+      type:
+        scalar: string
+    - name: selfLink
+      type:
+        scalar: string
+    - name: uid
+      type:
+        scalar: string
+- name: io.k8s.apimachinery.pkg.apis.meta.v1.OwnerReference
+  map:
+    fields:
+    - name: apiVersion
+      type:
+        scalar: string
+      default: ""
+    - name: blockOwnerDeletion
+      type:
+        scalar: boolean
+    - name: controller
+      type:
+        scalar: boolean
+    - name: kind
+      type:
+        scalar: string
+      default: ""
+    - name: name
+      type:
+        scalar: string
+      default: ""
+    - name: uid
+      type:
+        scalar: string
+      default: ""
+    elementRelationship: atomic
+- name: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+  scalar: untyped
+- name: io.k8s.apimachinery.pkg.runtime.RawExtension
+  map:
+    elementType:
+      scalar: untyped
+      list:
+        elementType:
+          namedType: __untyped_atomic_
+        elementRelationship: atomic
+      map:
+        elementType:
+          namedType: __untyped_deduced_
+        elementRelationship: separable
+- name: io.k8s.sample-controller.pkg.apis.calico.v1alpha1.Bookstore
+  map:
+    fields:
+    - name: apiVersion
+      type:
+        scalar: string
+    - name: kind
+      type:
+        scalar: string
+    - name: metadata
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta
+      default: {}
+    - name: spec
+      type:
+        namedType: io.k8s.sample-controller.pkg.apis.calico.v1alpha1.BookstoreSpec
+      default: {}
+    - name: status
+      type:
+        namedType: io.k8s.sample-controller.pkg.apis.calico.v1alpha1.BookstoreStatus
+      default: {}
+- name: io.k8s.sample-controller.pkg.apis.calico.v1alpha1.BookstoreSpec
+  map:
+    fields:
+    - name: configMapRef
+      type:
+        namedType: io.k8s.api.core.v1.LocalObjectReference
+    - name: containerPort
+      type:
+        scalar: numeric
+      default: 0
+    - name: deletionPolicy
+      type:
+        scalar: string
+    - name: deploymentImageName
+      type:
+        scalar: string
+      default: ""
+    - name: deploymentImageTag
+      type:
+        scalar: string
+      default: ""
+    - name: deploymentName
+      type:
+        scalar: string
+    - name: envAdminPassword
+      type:
+        scalar: string
+      default: ""
+    - name: envAdminUsername
+      type:
+        scalar: string
+      default: ""
+    - name: envJWTSECRET
+      type:
+        scalar: string
+      default: ""
+    - name: generateSecret
+      type:
+        scalar: boolean
+    - name: imagePullPolicy
+      type:
+        scalar: string
+    - name: nodePort
+      type:
+        scalar: numeric
+      default: 0
+    - name: podTemplate
+      type:
+        namedType: __untyped_atomic_
+    - name: replicas
+      type:
+        scalar: numeric
+    - name: secretRef
+      type:
+        namedType: io.k8s.api.core.v1.LocalObjectReference
+    - name: serviceName
+      type:
+        scalar: string
+    - name: serviceType
+      type:
+        scalar: string
+    - name: targetPort
+      type:
+        scalar: numeric
+    - name: workload
+      type:
+        namedType: io.k8s.sample-controller.pkg.apis.calico.v1alpha1.WorkloadSpec
+      default: {}
+- name: io.k8s.sample-controller.pkg.apis.calico.v1alpha1.BookstoreStatus
+  map:
+    fields:
+    - name: availableReplicas
+      type:
+        scalar: numeric
+      default: 0
+    - name: conditions
+      type:
+        list:
+          elementType:
+            namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Condition
+          elementRelationship: associative
+          keys:
+          - type
+    - name: observedGeneration
+      type:
+        scalar: numeric
+    - name: replicas
+      type:
+        scalar: numeric
+    - name: secretName
+      type:
+        scalar: string
+    - name: selector
+      type:
+        scalar: string
+- name: io.k8s.sample-controller.pkg.apis.calico.v1alpha1.ProbesSpec
+  map:
+    fields:
+    - name: livenessPath
+      type:
+        scalar: string
+    - name: readinessPath
+      type:
+        scalar: string
+    - name: startupPath
+      type:
+        scalar: string
+- name: io.k8s.sample-controller.pkg.apis.calico.v1alpha1.WorkloadSpec
+  map:
+    fields:
+    - name: preStopSleepSeconds
+      type:
+        scalar: numeric
+    - name: probes
+      type:
+        namedType: io.k8s.sample-controller.pkg.apis.calico.v1alpha1.ProbesSpec
+      default: {}
+    - name: resources
+      type:
+        namedType: io.k8s.api.core.v1.ResourceRequirements
+    - name: terminationGracePeriodSeconds
+      type:
+        scalar: numeric
+- name: io.k8s.sample-controller.pkg.apis.calico.v1beta1.Bookstore
+  map:
+    fields:
+    - name: apiVersion
+      type:
+        scalar: string
+    - name: kind
+      type:
+        scalar: string
+    - name: metadata
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta
+      default: {}
+    - name: spec
+      type:
+        namedType: io.k8s.sample-controller.pkg.apis.calico.v1beta1.BookstoreSpec
+      default: {}
+    - name: status
+      type:
+        namedType: io.k8s.sample-controller.pkg.apis.calico.v1beta1.BookstoreStatus
+      default: {}
+- name: io.k8s.sample-controller.pkg.apis.calico.v1beta1.BookstoreSpec
+  map:
+    fields:
+    - name: credentials
+      type:
+        namedType: io.k8s.sample-controller.pkg.apis.calico.v1beta1.CredentialsSpec
+      default: {}
+    - name: deletionPolicy
+      type:
+        scalar: string
+    - name: image
+      type:
+        namedType: io.k8s.sample-controller.pkg.apis.calico.v1beta1.ImageSpec
+      default: {}
+    - name: service
+      type:
+        namedType: io.k8s.sample-controller.pkg.apis.calico.v1beta1.ServiceSpec
+      default: {}
+    - name: workload
+      type:
+        namedType: io.k8s.sample-controller.pkg.apis.calico.v1beta1.WorkloadSpec
+      default: {}
+- name: io.k8s.sample-controller.pkg.apis.calico.v1beta1.BookstoreStatus
+  map:
+    fields:
+    - name: availableReplicas
+      type:
+        scalar: numeric
+      default: 0
+    - name: conditions
+      type:
+        list:
+          elementType:
+            namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Condition
+          elementRelationship: associative
+          keys:
+          - type
+    - name: observedGeneration
+      type:
+        scalar: numeric
+    - name: replicas
+      type:
+        scalar: numeric
+    - name: secretName
+      type:
+        scalar: string
+    - name: selector
+      type:
+        scalar: string
+- name: io.k8s.sample-controller.pkg.apis.calico.v1beta1.CredentialsSpec
+  map:
+    fields:
+    - name: adminPasswordKey
+      type:
+        scalar: string
+      default: ""
+    - name: adminUsernameKey
+      type:
+        scalar: string
+      default: ""
+    - name: generate
+      type:
+        scalar: boolean
+    - name: jwtSecretKey
+      type:
+        scalar: string
+      default: ""
+    - name: secretRef
+      type:
+        namedType: io.k8s.api.core.v1.LocalObjectReference
+- name: io.k8s.sample-controller.pkg.apis.calico.v1beta1.ImageSpec
+  map:
+    fields:
+    - name: name
+      type:
+        scalar: string
+      default: ""
+    - name: pullPolicy
+      type:
+        scalar: string
+    - name: tag
+      type:
+        scalar: string
+      default: ""
+- name: io.k8s.sample-controller.pkg.apis.calico.v1beta1.ProbesSpec
+  map:
+    fields:
+    - name: livenessPath
+      type:
+        scalar: string
+    - name: readinessPath
+      type:
+        scalar: string
+    - name: startupPath
+      type:
+        scalar: string
+- name: io.k8s.sample-controller.pkg.apis.calico.v1beta1.ServiceSpec
+  map:
+    fields:
+    - name: name
+      type:
+        scalar: string
+    - name: nodePort
+      type:
+        scalar: numeric
+    - name: targetPort
+      type:
+        scalar: numeric
+    - name: type
+      type:
+        scalar: string
+- name: io.k8s.sample-controller.pkg.apis.calico.v1beta1.WorkloadSpec
+  map:
+    fields:
+    - name: configMapRef
+      type:
+        namedType: io.k8s.api.core.v1.LocalObjectReference
+    - name: containerPort
+      type:
+        scalar: numeric
+      default: 0
+    - name: deploymentName
+      type:
+        scalar: string
+    - name: podTemplate
+      type:
+        namedType: __untyped_atomic_
+    - name: preStopSleepSeconds
+      type:
+        scalar: numeric
+    - name: probes
+      type:
+        namedType: io.k8s.sample-controller.pkg.apis.calico.v1beta1.ProbesSpec
+      default: {}
+    - name: replicas
+      type:
+        scalar: numeric
+    - name: resources
+      type:
+        namedType: io.k8s.api.core.v1.ResourceRequirements
+    - name: terminationGracePeriodSeconds
+      type:
+        scalar: numeric
+- name: __untyped_atomic_
+  scalar: untyped
+  list:
+    elementType:
+      namedType: __untyped_atomic_
+    elementRelationship: atomic
+  map:
+    elementType:
+      namedType: __untyped_atomic_
+    elementRelationship: atomic
+- name: __untyped_deduced_
+  scalar: untyped
+  list:
+    elementType:
+      namedType: __untyped_atomic_
+    elementRelationship: atomic
+  map:
+    elementType:
+      namedType: __untyped_deduced_
+    elementRelationship: separable
+`)
@@ -0,0 +1,65 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package applyconfiguration
+
+import (
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	v1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+	v1beta1 "k8s.io/sample-controller/pkg/apis/calico/v1beta1"
+	calicov1alpha1 "k8s.io/sample-controller/pkg/generated/applyconfiguration/calico/v1alpha1"
+	calicov1beta1 "k8s.io/sample-controller/pkg/generated/applyconfiguration/calico/v1beta1"
+)
+
+// ForKind returns an apply configuration type for the given GroupVersionKind, or nil if no
+// apply configuration type exists for the given GroupVersionKind.
+func ForKind(kind schema.GroupVersionKind) interface{} {
+	switch kind {
+	// Group=calico.com, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithKind("Bookstore"):
+		return &calicov1alpha1.BookstoreApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("BookstoreSpec"):
+		return &calicov1alpha1.BookstoreSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("BookstoreStatus"):
+		return &calicov1alpha1.BookstoreStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ProbesSpec"):
+		return &calicov1alpha1.ProbesSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadSpec"):
+		return &calicov1alpha1.WorkloadSpecApplyConfiguration{}
+
+		// Group=calico.com, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithKind("Bookstore"):
+		return &calicov1beta1.BookstoreApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("BookstoreSpec"):
+		return &calicov1beta1.BookstoreSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("BookstoreStatus"):
+		return &calicov1beta1.BookstoreStatusApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("CredentialsSpec"):
+		return &calicov1beta1.CredentialsSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("ImageSpec"):
+		return &calicov1beta1.ImageSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("ProbesSpec"):
+		return &calicov1beta1.ProbesSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("ServiceSpec"):
+		return &calicov1beta1.ServiceSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("WorkloadSpec"):
+		return &calicov1beta1.WorkloadSpecApplyConfiguration{}
+
+	}
+	return nil
+}
@@ -26,17 +26,20 @@ import (
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
 	calicov1alpha1 "k8s.io/sample-controller/pkg/generated/clientset/versioned/typed/calico/v1alpha1"
+	calicov1beta1 "k8s.io/sample-controller/pkg/generated/clientset/versioned/typed/calico/v1beta1"
 )
 
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	CalicoV1alpha1() calicov1alpha1.CalicoV1alpha1Interface
+	CalicoV1beta1() calicov1beta1.CalicoV1beta1Interface
 }
 
 // Clientset contains the clients for groups.
 type Clientset struct {
 	*discovery.DiscoveryClient
 	calicoV1alpha1 *calicov1alpha1.CalicoV1alpha1Client
+	calicoV1beta1  *calicov1beta1.CalicoV1beta1Client
 }
 
 // CalicoV1alpha1 retrieves the CalicoV1alpha1Client
@@ -44,6 +47,11 @@ func (c *Clientset) CalicoV1alpha1() calicov1alpha1.CalicoV1alpha1Interface {
 	return c.calicoV1alpha1
 }
 
+// CalicoV1beta1 retrieves the CalicoV1beta1Client
+func (c *Clientset) CalicoV1beta1() calicov1beta1.CalicoV1beta1Interface {
+	return c.calicoV1beta1
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -92,6 +100,10 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 	if err != nil {
 		return nil, err
 	}
+	cs.calicoV1beta1, err = calicov1beta1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
@@ -114,6 +126,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.calicoV1alpha1 = calicov1alpha1.New(c)
+	cs.calicoV1beta1 = calicov1beta1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
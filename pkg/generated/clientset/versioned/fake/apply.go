@@ -0,0 +1,67 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file is not generated. The client-gen in use predates fake clientsets
+// backed by a field managed tracker; drop it once client-gen generates
+// NewClientset.
+
+package fake
+
+import (
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/testing"
+)
+
+// NewClientset returns a clientset like NewSimpleClientset that also
+// supports Apply: an apply patch creates the object if it does not exist yet,
+// and is merged into it otherwise. Field ownership is not tracked, so
+// managedFields stay empty and Extract functions return no fields.
+func NewClientset(objects ...runtime.Object) *Clientset {
+	cs := NewSimpleClientset(objects...)
+	cs.PrependReactor("patch", "*", ApplyReaction(cs.Tracker(), codecs.UniversalDeserializer()))
+	return cs
+}
+
+// ApplyReaction returns a reactor that creates the object of an apply patch
+// in tracker when it does not exist yet, decoding the patch with decoder.
+// Apply patches to existing objects and to subresources are left to
+// testing.ObjectReaction, which merges them like strategic merge patches.
+// It works with the tracker of any fake clientset whose types decoder knows,
+// e.g. k8s.io/client-go/kubernetes/fake with scheme.Codecs.
+func ApplyReaction(tracker testing.ObjectTracker, decoder runtime.Decoder) testing.ReactionFunc {
+	return func(action testing.Action) (bool, runtime.Object, error) {
+		patch, ok := action.(testing.PatchAction)
+		if !ok || patch.GetPatchType() != types.ApplyPatchType || patch.GetSubresource() != "" {
+			return false, nil, nil
+		}
+		gvr, ns, name := patch.GetResource(), patch.GetNamespace(), patch.GetName()
+		if _, err := tracker.Get(gvr, ns, name); !errors.IsNotFound(err) {
+			return false, nil, nil
+		}
+
+		obj, _, err := decoder.Decode(patch.GetPatch(), nil, nil)
+		if err != nil {
+			return true, nil, err
+		}
+		if err := tracker.Create(gvr, obj, ns); err != nil {
+			return true, nil, err
+		}
+		obj, err = tracker.Get(gvr, ns, name)
+		return true, obj, err
+	}
+}
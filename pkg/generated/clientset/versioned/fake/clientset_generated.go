@@ -27,6 +27,8 @@ import (
 	clientset "k8s.io/sample-controller/pkg/generated/clientset/versioned"
 	calicov1alpha1 "k8s.io/sample-controller/pkg/generated/clientset/versioned/typed/calico/v1alpha1"
 	fakecalicov1alpha1 "k8s.io/sample-controller/pkg/generated/clientset/versioned/typed/calico/v1alpha1/fake"
+	calicov1beta1 "k8s.io/sample-controller/pkg/generated/clientset/versioned/typed/calico/v1beta1"
+	fakecalicov1beta1 "k8s.io/sample-controller/pkg/generated/clientset/versioned/typed/calico/v1beta1/fake"
 )
 
 // NewSimpleClientset returns a clientset that will respond with the provided objects.
@@ -83,3 +85,8 @@ var (
 func (c *Clientset) CalicoV1alpha1() calicov1alpha1.CalicoV1alpha1Interface {
 	return &fakecalicov1alpha1.FakeCalicoV1alpha1{Fake: &c.Fake}
 }
+
+// CalicoV1beta1 retrieves the CalicoV1beta1Client
+func (c *Clientset) CalicoV1beta1() calicov1beta1.CalicoV1beta1Interface {
+	return &fakecalicov1beta1.FakeCalicoV1beta1{Fake: &c.Fake}
+}
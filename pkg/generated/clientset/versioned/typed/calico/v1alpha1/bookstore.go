@@ -20,13 +20,17 @@ package v1alpha1
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 	"time"
 
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
 	rest "k8s.io/client-go/rest"
 	v1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+	calicov1alpha1 "k8s.io/sample-controller/pkg/generated/applyconfiguration/calico/v1alpha1"
 	scheme "k8s.io/sample-controller/pkg/generated/clientset/versioned/scheme"
 )
 
@@ -47,6 +51,11 @@ type BookstoreInterface interface {
 	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.BookstoreList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
 	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.Bookstore, err error)
+	Apply(ctx context.Context, bookstore *calicov1alpha1.BookstoreApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.Bookstore, err error)
+	ApplyStatus(ctx context.Context, bookstore *calicov1alpha1.BookstoreApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.Bookstore, err error)
+	GetScale(ctx context.Context, bookstoreName string, options v1.GetOptions) (*autoscalingv1.Scale, error)
+	UpdateScale(ctx context.Context, bookstoreName string, scale *autoscalingv1.Scale, opts v1.UpdateOptions) (*autoscalingv1.Scale, error)
+
 	BookstoreExpansion
 }
 
@@ -193,3 +202,88 @@ func (c *bookstores) Patch(ctx context.Context, name string, pt types.PatchType,
 		Into(result)
 	return
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied bookstore.
+func (c *bookstores) Apply(ctx context.Context, bookstore *calicov1alpha1.BookstoreApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.Bookstore, err error) {
+	if bookstore == nil {
+		return nil, fmt.Errorf("bookstore provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(bookstore)
+	if err != nil {
+		return nil, err
+	}
+	name := bookstore.Name
+	if name == nil {
+		return nil, fmt.Errorf("bookstore.Name must be provided to Apply")
+	}
+	result = &v1alpha1.Bookstore{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("bookstores").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *bookstores) ApplyStatus(ctx context.Context, bookstore *calicov1alpha1.BookstoreApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.Bookstore, err error) {
+	if bookstore == nil {
+		return nil, fmt.Errorf("bookstore provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(bookstore)
+	if err != nil {
+		return nil, err
+	}
+
+	name := bookstore.Name
+	if name == nil {
+		return nil, fmt.Errorf("bookstore.Name must be provided to Apply")
+	}
+
+	result = &v1alpha1.Bookstore{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("bookstores").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// GetScale takes name of the bookstore, and returns the corresponding autoscalingv1.Scale object, and an error if there is any.
+func (c *bookstores) GetScale(ctx context.Context, bookstoreName string, options v1.GetOptions) (result *autoscalingv1.Scale, err error) {
+	result = &autoscalingv1.Scale{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("bookstores").
+		Name(bookstoreName).
+		SubResource("scale").
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateScale takes the top resource name and the representation of a scale and updates it. Returns the server's representation of the scale, and an error, if there is any.
+func (c *bookstores) UpdateScale(ctx context.Context, bookstoreName string, scale *autoscalingv1.Scale, opts v1.UpdateOptions) (result *autoscalingv1.Scale, err error) {
+	result = &autoscalingv1.Scale{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("bookstores").
+		Name(bookstoreName).
+		SubResource("scale").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(scale).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -31,7 +31,7 @@ type CalicoV1alpha1Interface interface {
 	BookstoresGetter
 }
 
-// CalicoV1alpha1Client is used to interact with features provided by the calico group.
+// CalicoV1alpha1Client is used to interact with features provided by the calico.com group.
 type CalicoV1alpha1Client struct {
 	restClient rest.Interface
 }
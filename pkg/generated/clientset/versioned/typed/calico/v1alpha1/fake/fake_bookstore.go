@@ -20,13 +20,17 @@ package fake
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	types "k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
 	testing "k8s.io/client-go/testing"
 	v1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+	calicov1alpha1 "k8s.io/sample-controller/pkg/generated/applyconfiguration/calico/v1alpha1"
 )
 
 // FakeBookstores implements BookstoreInterface
@@ -145,3 +149,74 @@ func (c *FakeBookstores) Patch(ctx context.Context, name string, pt types.PatchT
 	}
 	return obj.(*v1alpha1.Bookstore), err
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied bookstore.
+func (c *FakeBookstores) Apply(ctx context.Context, bookstore *calicov1alpha1.BookstoreApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.Bookstore, err error) {
+	if bookstore == nil {
+		return nil, fmt.Errorf("bookstore provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(bookstore)
+	if err != nil {
+		return nil, err
+	}
+	name := bookstore.Name
+	if name == nil {
+		return nil, fmt.Errorf("bookstore.Name must be provided to Apply")
+	}
+	emptyResult := &v1alpha1.Bookstore{}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(bookstoresResource, c.ns, *name, types.ApplyPatchType, data), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1alpha1.Bookstore), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeBookstores) ApplyStatus(ctx context.Context, bookstore *calicov1alpha1.BookstoreApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.Bookstore, err error) {
+	if bookstore == nil {
+		return nil, fmt.Errorf("bookstore provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(bookstore)
+	if err != nil {
+		return nil, err
+	}
+	name := bookstore.Name
+	if name == nil {
+		return nil, fmt.Errorf("bookstore.Name must be provided to Apply")
+	}
+	emptyResult := &v1alpha1.Bookstore{}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(bookstoresResource, c.ns, *name, types.ApplyPatchType, data, "status"), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1alpha1.Bookstore), err
+}
+
+// GetScale takes name of the bookstore, and returns the corresponding scale object, and an error if there is any.
+func (c *FakeBookstores) GetScale(ctx context.Context, bookstoreName string, options v1.GetOptions) (result *autoscalingv1.Scale, err error) {
+	emptyResult := &autoscalingv1.Scale{}
+	obj, err := c.Fake.
+		Invokes(testing.NewGetSubresourceAction(bookstoresResource, c.ns, "scale", bookstoreName), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*autoscalingv1.Scale), err
+}
+
+// UpdateScale takes the representation of a scale and updates it. Returns the server's representation of the scale, and an error, if there is any.
+func (c *FakeBookstores) UpdateScale(ctx context.Context, bookstoreName string, scale *autoscalingv1.Scale, opts v1.UpdateOptions) (result *autoscalingv1.Scale, err error) {
+	emptyResult := &autoscalingv1.Scale{}
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(bookstoresResource, "scale", c.ns, scale), &autoscalingv1.Scale{})
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*autoscalingv1.Scale), err
+}
@@ -24,6 +24,7 @@ import (
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 	v1alpha1 "k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+	v1beta1 "k8s.io/sample-controller/pkg/apis/calico/v1beta1"
 )
 
 // GenericInformer is type of SharedIndexInformer which will locate and delegate to other
@@ -52,10 +53,14 @@ func (f *genericInformer) Lister() cache.GenericLister {
 // TODO extend this to unknown resources with a client pool
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
-	// Group=calico, Version=v1alpha1
+	// Group=calico.com, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("bookstores"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Calico().V1alpha1().Bookstores().Informer()}, nil
 
+		// Group=calico.com, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("bookstores"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Calico().V1beta1().Bookstores().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
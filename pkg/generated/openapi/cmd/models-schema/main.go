@@ -0,0 +1,92 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// models-schema prints the OpenAPI v2 definitions of the Bookstore API to
+// stdout. hack/update-codegen.sh feeds them to applyconfiguration-gen, which
+// embeds them so that apply configurations can be extracted from objects.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"k8s.io/sample-controller/pkg/generated/openapi"
+)
+
+func main() {
+	if err := output(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// output prints the definitions in the form served by an API server, with
+// the names reversed to their friendly form, e.g. io.k8s.api.core.v1.Pod.
+func output() error {
+	refFunc := func(name string) spec.Ref {
+		return spec.MustCreateRef("#/definitions/" + friendlyName(name))
+	}
+	defs := openapi.GetOpenAPIDefinitions(refFunc)
+	schemaDefs := make(map[string]spec.Schema, len(defs))
+	for name, def := range defs {
+		// Prefer the v2 schema where a type embeds one, so that the output
+		// is always OpenAPI v2.
+		if schema, ok := def.Schema.Extensions[common.ExtensionV2Schema]; ok {
+			if v2Schema, ok := schema.(spec.Schema); ok {
+				schemaDefs[friendlyName(name)] = v2Schema
+				continue
+			}
+		}
+		schemaDefs[friendlyName(name)] = def.Schema
+	}
+	data, err := json.Marshal(&spec.Swagger{
+		SwaggerProps: spec.SwaggerProps{
+			Definitions: schemaDefs,
+			Info: &spec.Info{
+				InfoProps: spec.InfoProps{
+					Title:   "Bookstore",
+					Version: "unversioned",
+				},
+			},
+			Swagger: "2.0",
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling definitions: %w", err)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// friendlyName turns a Go package path into the name an API server gives the
+// definition, reversing the domain: k8s.io/api/core/v1.Pod becomes
+// io.k8s.api.core.v1.Pod.
+func friendlyName(name string) string {
+	nameParts := strings.Split(name, "/")
+	if len(nameParts) > 0 && strings.Contains(nameParts[0], ".") {
+		parts := strings.Split(nameParts[0], ".")
+		for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+			parts[i], parts[j] = parts[j], parts[i]
+		}
+		nameParts[0] = strings.Join(parts, ".")
+	}
+	return strings.Join(nameParts, ".")
+}
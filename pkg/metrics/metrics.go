@@ -0,0 +1,65 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics exposes the Prometheus metrics of the controller: its
+// workqueue, its client-go requests and the outcome of its syncs.
+package metrics
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	clientmetrics "k8s.io/client-go/tools/metrics"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Registry holds every metric of the controller. It is not the default
+// Prometheus registry, so that only the metrics registered here are served.
+var Registry = prometheus.NewRegistry()
+
+var registerOnce sync.Once
+
+// Register registers the metrics with Registry and hooks them into
+// client-go. It must be called before the workqueue and the clientsets are
+// created, since client-go only picks up its metrics providers once.
+func Register() {
+	registerOnce.Do(func() {
+		Registry.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+
+		Registry.MustRegister(
+			depth, adds, latency, workDuration, unfinished, longestRunningProcessor, retries,
+			requestLatency, rateLimiterLatency, requestResult,
+			syncTotal, syncDuration,
+		)
+		workqueue.SetProvider(workqueueMetricsProvider{})
+		clientmetrics.Register(clientmetrics.RegisterOpts{
+			RequestLatency:     &latencyAdapter{metric: requestLatency},
+			RateLimiterLatency: &latencyAdapter{metric: rateLimiterLatency},
+			RequestResult:      &resultAdapter{metric: requestResult},
+		})
+	})
+}
+
+// Handler serves the metrics in Registry.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The results a Bookstore sync is counted under.
+const (
+	// SyncResultSuccess is a sync that converged the Bookstore.
+	SyncResultSuccess = "success"
+	// SyncResultConflict is a sync that failed on a write based on a stale
+	// version of an object, and is retried at once.
+	SyncResultConflict = "conflict"
+	// SyncResultRateLimited is a sync that failed because the API server
+	// rejected a request as one too many, and is retried after the delay it
+	// asked for.
+	SyncResultRateLimited = "rate_limited"
+	// SyncResultTransientError is a sync that failed in any other way, and is
+	// retried with backoff.
+	SyncResultTransientError = "transient_error"
+	// SyncResultInvalidSpec is a sync of a Bookstore whose spec cannot be
+	// converged, which is not retried until the spec changes.
+	SyncResultInvalidSpec = "invalid_spec"
+)
+
+var (
+	syncTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "bookstore",
+		Name:      "sync_total",
+		Help:      "Number of Bookstore syncs, partitioned by result.",
+	}, []string{"result"})
+
+	syncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: "bookstore",
+		Name:      "sync_duration_seconds",
+		Help:      "Duration in seconds of Bookstore syncs, partitioned by result.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+	}, []string{"result"})
+)
+
+// ObserveSync records a Bookstore sync that ended with result after
+// duration.
+func ObserveSync(result string, duration time.Duration) {
+	syncTotal.WithLabelValues(result).Inc()
+	syncDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
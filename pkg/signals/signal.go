@@ -42,3 +42,33 @@ func SetupSignalHandler() context.Context {
 
 	return ctx
 }
+
+// SetupReloadHandler registers for SIGHUP. The returned channel receives a
+// value on every SIGHUP until ctx is cancelled. Signals that arrive while the
+// previous one is still pending are coalesced, so a receiver that is slow to
+// reload does not reload again for each of them.
+func SetupReloadHandler(ctx context.Context) <-chan struct{} {
+	reload := make(chan struct{}, 1)
+	if len(reloadSignals) == 0 {
+		return reload
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, reloadSignals...)
+	go func() {
+		defer signal.Stop(c)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c:
+				select {
+				case reload <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return reload
+}
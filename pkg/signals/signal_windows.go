@@ -21,3 +21,6 @@ import (
 )
 
 var shutdownSignals = []os.Signal{os.Interrupt}
+
+// There is no SIGHUP on Windows, so the configuration is never reloaded.
+var reloadSignals []os.Signal
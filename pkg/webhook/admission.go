@@ -0,0 +1,130 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// AdmitFunc decides on a single admission request.
+type AdmitFunc func(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse
+
+// AdmissionHandler adapts admit to an http.Handler that speaks the
+// admission.k8s.io/v1 AdmissionReview protocol.
+func AdmissionHandler(admit AdmitFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := klog.FromContext(r.Context())
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		review := &admissionv1.AdmissionReview{}
+		if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+			http.Error(w, fmt.Sprintf("malformed admission review: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		response := admit(r.Context(), review.Request)
+		response.UID = review.Request.UID
+		review.Response = response
+		review.Request = nil
+
+		logger.V(4).Info("Admission review", "path", r.URL.Path, "uid", response.UID, "allowed", response.Allowed)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(review); err != nil {
+			logger.Error(err, "Error writing admission response")
+		}
+	})
+}
+
+// allowed returns a response admitting the request.
+func allowed() *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+// denied returns a response rejecting the request with status.
+func denied(status metav1.Status) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{Allowed: false, Result: &status}
+}
+
+// jsonPatchOperation is a single JSON patch (RFC 6902) operation.
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// patched returns a response admitting the request with a JSON patch that
+// sets every top-level field of the object at path that differs between
+// original and mutated, which must be of the same type.
+func patched(original, mutated interface{}, path string) (*admissionv1.AdmissionResponse, error) {
+	originalFields, err := toFields(original)
+	if err != nil {
+		return nil, err
+	}
+	mutatedFields, err := toFields(mutated)
+	if err != nil {
+		return nil, err
+	}
+
+	var operations []jsonPatchOperation
+	for name, value := range mutatedFields {
+		if !reflect.DeepEqual(originalFields[name], value) {
+			// "add" replaces the member if it already exists.
+			operations = append(operations, jsonPatchOperation{Op: "add", Path: path + "/" + escapeJSONPointer(name), Value: value})
+		}
+	}
+	if len(operations) == 0 {
+		return allowed(), nil
+	}
+	sort.Slice(operations, func(i, j int) bool { return operations[i].Path < operations[j].Path })
+
+	patch, err := json.Marshal(operations)
+	if err != nil {
+		return nil, err
+	}
+	patchType := admissionv1.PatchTypeJSONPatch
+	return &admissionv1.AdmissionResponse{Allowed: true, Patch: patch, PatchType: &patchType}, nil
+}
+
+// toFields round-trips obj through JSON into a map of its top-level fields.
+func toFields(obj interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	return fields, json.Unmarshal(data, &fields)
+}
+
+// escapeJSONPointer escapes a reference token of a JSON pointer (RFC 6901).
+func escapeJSONPointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+
+	"k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+	"k8s.io/sample-controller/pkg/apis/calico/validation"
+)
+
+const (
+	// ValidateBookstorePath is the path the Bookstore validating webhook is
+	// served at.
+	ValidateBookstorePath = "/validate-calico-com-v1alpha1-bookstore"
+	// DefaultBookstorePath is the path the Bookstore defaulting webhook is
+	// served at.
+	DefaultBookstorePath = "/mutate-calico-com-v1alpha1-bookstore"
+)
+
+// DefaultBookstore returns an AdmitFunc that fills in the derived fields of
+// a Bookstore spec using the defaulting functions of its API version.
+func DefaultBookstore() AdmitFunc {
+	return func(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		bookstore := &v1alpha1.Bookstore{}
+		if err := json.Unmarshal(req.Object.Raw, bookstore); err != nil {
+			return denied(apierrors.NewBadRequest(err.Error()).ErrStatus)
+		}
+
+		defaulted := bookstore.DeepCopy()
+		v1alpha1.SetObjectDefaults_Bookstore(defaulted)
+
+		response, err := patched(&bookstore.Spec, &defaulted.Spec, "/spec")
+		if err != nil {
+			return denied(apierrors.NewInternalError(err).ErrStatus)
+		}
+		return response
+	}
+}
+
+// ValidateBookstore returns an AdmitFunc that rejects Bookstores whose spec
+// fails validation, reporting every offending field. nodePortRange is the
+// range the cluster allocates node ports from.
+func ValidateBookstore(nodePortRange utilnet.PortRange) AdmitFunc {
+	return func(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		bookstore := &v1alpha1.Bookstore{}
+		if err := json.Unmarshal(req.Object.Raw, bookstore); err != nil {
+			return denied(apierrors.NewBadRequest(err.Error()).ErrStatus)
+		}
+
+		if errs := validation.ValidateBookstore(bookstore, nodePortRange); len(errs) > 0 {
+			return denied(apierrors.NewInvalid(v1alpha1.Kind("Bookstore"), req.Name, errs).ErrStatus)
+		}
+		return allowed()
+	}
+}
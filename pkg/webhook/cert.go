@@ -0,0 +1,197 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+)
+
+const (
+	// CertName is the name of the serving certificate file in the cert dir.
+	CertName = "tls.crt"
+	// KeyName is the name of the serving key file in the cert dir.
+	KeyName = "tls.key"
+	// CAName is the key of the CA in the serving certificate Secret.
+	CAName = "ca.crt"
+
+	// renewBefore is how long before expiry a certificate is replaced.
+	renewBefore = 30 * 24 * time.Hour
+)
+
+// EnsureServingCert makes sure certDir holds a serving certificate valid for
+// all of hosts. A new self-signed certificate is generated if none exists,
+// if it is about to expire or if it does not cover every host. It returns the
+// PEM bundle the API server must trust to call the webhook server.
+//
+// Replicas that each run EnsureServingCert generate their own CA, so it is
+// only suitable for a single replica. Several replicas share a certificate
+// through EnsureServingCertSecret instead.
+func EnsureServingCert(certDir string, hosts []string) ([]byte, error) {
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("at least one host is required for the serving certificate")
+	}
+	certPath := filepath.Join(certDir, CertName)
+	keyPath := filepath.Join(certDir, KeyName)
+
+	if certPEM, err := os.ReadFile(certPath); err == nil && certValidFor(certPEM, hosts) {
+		if _, err := os.Stat(keyPath); err == nil {
+			return caBundle(certPEM)
+		}
+	}
+
+	certPEM, keyPEM, err := generateServingCert(hosts)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeServingCert(certDir, certPEM, keyPEM); err != nil {
+		return nil, err
+	}
+	return caBundle(certPEM)
+}
+
+// EnsureServingCertSecret is EnsureServingCert for replicas sharing one
+// certificate. The certificate, its key and the CA are kept in the Secret
+// namespace/name, which the first replica to start creates and the others
+// load. The certificate and key are then written to certDir, for the webhook
+// server to serve. Whichever replica finds the certificate about to expire,
+// or missing a host, replaces it; a replica that loses the race to create or
+// replace it uses the certificate of the winner. It returns the CA of the
+// Secret, the bundle the API server must trust.
+func EnsureServingCertSecret(ctx context.Context, client kubernetes.Interface, namespace, name, certDir string, hosts []string) ([]byte, error) {
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("at least one host is required for the serving certificate")
+	}
+	secrets := client.CoreV1().Secrets(namespace)
+	for attempt := 0; attempt < 3; attempt++ {
+		secret, err := secrets.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			secret, err = nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if secret != nil && len(secret.Data[KeyName]) > 0 && len(secret.Data[CAName]) > 0 && certValidFor(secret.Data[CertName], hosts) {
+			if err := writeServingCert(certDir, secret.Data[CertName], secret.Data[KeyName]); err != nil {
+				return nil, err
+			}
+			return secret.Data[CAName], nil
+		}
+
+		certPEM, keyPEM, err := generateServingCert(hosts)
+		if err != nil {
+			return nil, err
+		}
+		caPEM, err := caBundle(certPEM)
+		if err != nil {
+			return nil, err
+		}
+		data := map[string][]byte{CertName: certPEM, KeyName: keyPEM, CAName: caPEM}
+		if secret == nil {
+			_, err = secrets.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Type:       corev1.SecretTypeTLS,
+				Data:       data,
+			}, metav1.CreateOptions{})
+		} else {
+			secret = secret.DeepCopy()
+			secret.Data = data
+			_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+		}
+		if apierrors.IsAlreadyExists(err) || apierrors.IsConflict(err) {
+			// Another replica wrote the Secret first; use its certificate.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := writeServingCert(certDir, certPEM, keyPEM); err != nil {
+			return nil, err
+		}
+		return caPEM, nil
+	}
+	return nil, fmt.Errorf("secret %s/%s kept changing while ensuring the serving certificate", namespace, name)
+}
+
+// generateServingCert returns a new self-signed serving certificate for
+// hosts, followed by the CA that signed it, and its key.
+func generateServingCert(hosts []string) ([]byte, []byte, error) {
+	var ips []net.IP
+	var dnsNames []string
+	for _, host := range hosts[1:] {
+		if ip := net.ParseIP(host); ip != nil {
+			ips = append(ips, ip)
+		} else {
+			dnsNames = append(dnsNames, host)
+		}
+	}
+	certPEM, keyPEM, err := certutil.GenerateSelfSignedCertKey(hosts[0], ips, dnsNames)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating serving certificate: %w", err)
+	}
+	return certPEM, keyPEM, nil
+}
+
+// writeServingCert writes the serving certificate and key to certDir.
+func writeServingCert(certDir string, certPEM, keyPEM []byte) error {
+	if err := certutil.WriteCert(filepath.Join(certDir, CertName), certPEM); err != nil {
+		return err
+	}
+	return keyutil.WriteKey(filepath.Join(certDir, KeyName), keyPEM)
+}
+
+// caBundle returns the CA following the serving certificate in certPEM, as
+// written by generateServingCert.
+func caBundle(certPEM []byte) ([]byte, error) {
+	certs, err := certutil.ParseCertsPEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) < 2 {
+		return nil, fmt.Errorf("serving certificate is not followed by its CA")
+	}
+	return certutil.EncodeCertificates(certs[1:]...)
+}
+
+// certValidFor reports whether the first certificate in certPEM is valid
+// for every host and does not expire within renewBefore.
+func certValidFor(certPEM []byte, hosts []string) bool {
+	certs, err := certutil.ParseCertsPEM(certPEM)
+	if err != nil || len(certs) == 0 {
+		return false
+	}
+	if time.Now().Add(renewBefore).After(certs[0].NotAfter) {
+		return false
+	}
+	for _, host := range hosts {
+		if err := certs[0].VerifyHostname(host); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+// TestReplicasShareServingCert checks that replicas with their own cert dir
+// serve the same certificate and return the same CA bundle, which verifies
+// it.
+func TestReplicasShareServingCert(t *testing.T) {
+	ctx := context.Background()
+	client := k8sfake.NewSimpleClientset()
+	hosts := []string{"webhook.default.svc", "webhook.default.svc.cluster.local"}
+
+	var bundles, certs [][]byte
+	for i := 0; i < 2; i++ {
+		certDir := t.TempDir()
+		bundle, err := EnsureServingCertSecret(ctx, client, "default", "webhook-cert", certDir, hosts)
+		if err != nil {
+			t.Fatalf("replica %d: %v", i, err)
+		}
+		cert, err := os.ReadFile(filepath.Join(certDir, CertName))
+		if err != nil {
+			t.Fatalf("replica %d: %v", i, err)
+		}
+		bundles = append(bundles, bundle)
+		certs = append(certs, cert)
+	}
+	if !bytes.Equal(bundles[0], bundles[1]) {
+		t.Error("replicas returned different CA bundles")
+	}
+	if !bytes.Equal(certs[0], certs[1]) {
+		t.Error("replicas serve different certificates")
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(bundles[0]) {
+		t.Fatal("CA bundle holds no certificate")
+	}
+	served, err := certutil.ParseCertsPEM(certs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, host := range hosts {
+		if _, err := served[0].Verify(x509.VerifyOptions{DNSName: host, Roots: roots}); err != nil {
+			t.Errorf("serving certificate not trusted for %s: %v", host, err)
+		}
+	}
+}
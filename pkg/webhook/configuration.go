@@ -0,0 +1,237 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"k8s.io/sample-controller/pkg/apis/calico"
+	"k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+)
+
+const (
+	// ValidatingWebhookConfigurationName is the name of the
+	// ValidatingWebhookConfiguration managed by the controller.
+	ValidatingWebhookConfigurationName = "bookstores." + calico.GroupName
+	// MutatingWebhookConfigurationName is the name of the
+	// MutatingWebhookConfiguration managed by the controller.
+	MutatingWebhookConfigurationName = "bookstores." + calico.GroupName
+)
+
+// ClientConfig describes how the API server reaches the webhook server,
+// either through a Service or, when URL is set, at a fixed address such as
+// https://127.0.0.1:9443 for a local API server.
+type ClientConfig struct {
+	URL              string
+	ServiceNamespace string
+	ServiceName      string
+	ServicePort      int32
+	CABundle         []byte
+}
+
+// Hosts returns the names the serving certificate must be valid for.
+func (c ClientConfig) Hosts() ([]string, error) {
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			return nil, err
+		}
+		return []string{u.Hostname()}, nil
+	}
+	service := c.ServiceName + "." + c.ServiceNamespace + ".svc"
+	return []string{service, service + ".cluster.local"}, nil
+}
+
+// forPath returns the client config that points the API server at path.
+func (c ClientConfig) forPath(path string) admissionregistrationv1.WebhookClientConfig {
+	if c.URL != "" {
+		u := strings.TrimSuffix(c.URL, "/") + path
+		return admissionregistrationv1.WebhookClientConfig{URL: &u, CABundle: c.CABundle}
+	}
+	return admissionregistrationv1.WebhookClientConfig{
+		Service: &admissionregistrationv1.ServiceReference{
+			Namespace: c.ServiceNamespace,
+			Name:      c.ServiceName,
+			Path:      &path,
+			Port:      &c.ServicePort,
+		},
+		CABundle: c.CABundle,
+	}
+}
+
+// NewValidatingWebhookConfiguration returns the configuration that sends
+// Bookstore creates and updates to the validating webhook.
+func NewValidatingWebhookConfiguration(clientConfig ClientConfig) *admissionregistrationv1.ValidatingWebhookConfiguration {
+	failurePolicy := admissionregistrationv1.Fail
+	matchPolicy := admissionregistrationv1.Equivalent
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	return &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ValidatingWebhookConfigurationName,
+		},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{
+				Name:                    "validate." + ValidatingWebhookConfigurationName,
+				ClientConfig:            clientConfig.forPath(ValidateBookstorePath),
+				Rules:                   bookstoreRules(),
+				FailurePolicy:           &failurePolicy,
+				MatchPolicy:             &matchPolicy,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1"},
+			},
+		},
+	}
+}
+
+// EnsureValidatingWebhookConfiguration creates config, or updates the
+// existing configuration of the same name to match it. A configuration that
+// already matches is left alone, so that replicas sharing a certificate do
+// not keep rewriting it.
+func EnsureValidatingWebhookConfiguration(ctx context.Context, client kubernetes.Interface, config *admissionregistrationv1.ValidatingWebhookConfiguration) error {
+	configurations := client.AdmissionregistrationV1().ValidatingWebhookConfigurations()
+	existing, err := configurations.Get(ctx, config.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = configurations.Create(ctx, config, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if validatingWebhooksUpToDate(existing.Webhooks, config.Webhooks) {
+		return nil
+	}
+	config.ResourceVersion = existing.ResourceVersion
+	_, err = configurations.Update(ctx, config, metav1.UpdateOptions{})
+	return err
+}
+
+// NewMutatingWebhookConfiguration returns the configuration that sends
+// Bookstore creates and updates to the defaulting webhook.
+func NewMutatingWebhookConfiguration(clientConfig ClientConfig) *admissionregistrationv1.MutatingWebhookConfiguration {
+	failurePolicy := admissionregistrationv1.Fail
+	matchPolicy := admissionregistrationv1.Equivalent
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	reinvocationPolicy := admissionregistrationv1.NeverReinvocationPolicy
+	return &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: MutatingWebhookConfigurationName,
+		},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{
+			{
+				Name:                    "default." + MutatingWebhookConfigurationName,
+				ClientConfig:            clientConfig.forPath(DefaultBookstorePath),
+				Rules:                   bookstoreRules(),
+				FailurePolicy:           &failurePolicy,
+				MatchPolicy:             &matchPolicy,
+				SideEffects:             &sideEffects,
+				ReinvocationPolicy:      &reinvocationPolicy,
+				AdmissionReviewVersions: []string{"v1"},
+			},
+		},
+	}
+}
+
+// EnsureMutatingWebhookConfiguration creates config, or updates the existing
+// configuration of the same name to match it, unless it already matches.
+func EnsureMutatingWebhookConfiguration(ctx context.Context, client kubernetes.Interface, config *admissionregistrationv1.MutatingWebhookConfiguration) error {
+	configurations := client.AdmissionregistrationV1().MutatingWebhookConfigurations()
+	existing, err := configurations.Get(ctx, config.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = configurations.Create(ctx, config, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if mutatingWebhooksUpToDate(existing.Webhooks, config.Webhooks) {
+		return nil
+	}
+	config.ResourceVersion = existing.ResourceVersion
+	_, err = configurations.Update(ctx, config, metav1.UpdateOptions{})
+	return err
+}
+
+// bookstoreRules matches creates and updates of Bookstores.
+func bookstoreRules() []admissionregistrationv1.RuleWithOperations {
+	scope := admissionregistrationv1.NamespacedScope
+	return []admissionregistrationv1.RuleWithOperations{
+		{
+			Operations: []admissionregistrationv1.OperationType{
+				admissionregistrationv1.Create,
+				admissionregistrationv1.Update,
+			},
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   []string{calico.GroupName},
+				APIVersions: []string{v1alpha1.SchemeGroupVersion.Version},
+				Resources:   []string{"bookstores"},
+				Scope:       &scope,
+			},
+		},
+	}
+}
+
+// validatingWebhooksUpToDate reports whether the existing webhooks match the
+// desired ones in every field the controller sets. Fields it leaves unset
+// are defaulted by the API server and not compared.
+func validatingWebhooksUpToDate(existing, desired []admissionregistrationv1.ValidatingWebhook) bool {
+	if len(existing) != len(desired) {
+		return false
+	}
+	for i := range desired {
+		e, d := existing[i], desired[i]
+		if e.Name != d.Name ||
+			!equality.Semantic.DeepEqual(e.ClientConfig, d.ClientConfig) ||
+			!equality.Semantic.DeepEqual(e.Rules, d.Rules) ||
+			!equality.Semantic.DeepEqual(e.FailurePolicy, d.FailurePolicy) ||
+			!equality.Semantic.DeepEqual(e.MatchPolicy, d.MatchPolicy) ||
+			!equality.Semantic.DeepEqual(e.SideEffects, d.SideEffects) ||
+			!equality.Semantic.DeepEqual(e.AdmissionReviewVersions, d.AdmissionReviewVersions) {
+			return false
+		}
+	}
+	return true
+}
+
+// mutatingWebhooksUpToDate is validatingWebhooksUpToDate for mutating
+// webhooks.
+func mutatingWebhooksUpToDate(existing, desired []admissionregistrationv1.MutatingWebhook) bool {
+	if len(existing) != len(desired) {
+		return false
+	}
+	for i := range desired {
+		e, d := existing[i], desired[i]
+		if e.Name != d.Name ||
+			!equality.Semantic.DeepEqual(e.ClientConfig, d.ClientConfig) ||
+			!equality.Semantic.DeepEqual(e.Rules, d.Rules) ||
+			!equality.Semantic.DeepEqual(e.FailurePolicy, d.FailurePolicy) ||
+			!equality.Semantic.DeepEqual(e.MatchPolicy, d.MatchPolicy) ||
+			!equality.Semantic.DeepEqual(e.SideEffects, d.SideEffects) ||
+			!equality.Semantic.DeepEqual(e.ReinvocationPolicy, d.ReinvocationPolicy) ||
+			!equality.Semantic.DeepEqual(e.AdmissionReviewVersions, d.AdmissionReviewVersions) {
+			return false
+		}
+	}
+	return true
+}
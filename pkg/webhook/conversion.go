@@ -0,0 +1,219 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	"k8s.io/sample-controller/pkg/apis/calico"
+	"k8s.io/sample-controller/pkg/apis/calico/v1alpha1"
+	"k8s.io/sample-controller/pkg/apis/calico/v1beta1"
+)
+
+const (
+	// ConvertBookstorePath is the path the Bookstore conversion webhook is
+	// served at.
+	ConvertBookstorePath = "/convert"
+	// BookstoreCRDName is the name of the Bookstore CustomResourceDefinition.
+	BookstoreCRDName = "bookstores." + calico.GroupName
+)
+
+var (
+	conversionScheme = runtime.NewScheme()
+	conversionCodecs = serializer.NewCodecFactory(conversionScheme)
+
+	crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+)
+
+func init() {
+	utilruntime.Must(v1alpha1.AddToScheme(conversionScheme))
+	utilruntime.Must(v1beta1.AddToScheme(conversionScheme))
+}
+
+// conversionReview mirrors the apiextensions.k8s.io/v1 ConversionReview. It
+// is declared here so the controller does not depend on the whole
+// apiextensions-apiserver module for three small wire types.
+type conversionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *conversionRequest  `json:"request,omitempty"`
+	Response        *conversionResponse `json:"response,omitempty"`
+}
+
+type conversionRequest struct {
+	UID               types.UID              `json:"uid"`
+	DesiredAPIVersion string                 `json:"desiredAPIVersion"`
+	Objects           []runtime.RawExtension `json:"objects"`
+}
+
+type conversionResponse struct {
+	UID              types.UID              `json:"uid"`
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+	Result           metav1.Status          `json:"result"`
+}
+
+// ConvertBookstores returns an http.Handler serving the conversion webhook
+// of the Bookstore CRD. Every version converts through the v1beta1 hub.
+func ConvertBookstores() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := klog.FromContext(r.Context())
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		review := &conversionReview{}
+		if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+			http.Error(w, fmt.Sprintf("malformed conversion review: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		response := &conversionResponse{UID: review.Request.UID}
+		converted, err := convertObjects(review.Request.Objects, review.Request.DesiredAPIVersion)
+		if err != nil {
+			response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+		} else {
+			response.ConvertedObjects = converted
+			response.Result = metav1.Status{Status: metav1.StatusSuccess}
+		}
+		review.Response = response
+		review.Request = nil
+
+		logger.V(4).Info("Conversion review", "uid", response.UID, "status", response.Result.Status)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(review); err != nil {
+			logger.Error(err, "Error writing conversion response")
+		}
+	})
+}
+
+// convertObjects converts every object to desiredAPIVersion.
+func convertObjects(objects []runtime.RawExtension, desiredAPIVersion string) ([]runtime.RawExtension, error) {
+	gv, err := schema.ParseGroupVersion(desiredAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+	decoder := conversionCodecs.UniversalDeserializer()
+
+	converted := make([]runtime.RawExtension, 0, len(objects))
+	for _, object := range objects {
+		in, _, err := decoder.Decode(object.Raw, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		out, err := conversionScheme.ConvertToVersion(in, gv)
+		if err != nil {
+			return nil, err
+		}
+		raw, err := json.Marshal(out)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, runtime.RawExtension{Raw: raw})
+	}
+	return converted, nil
+}
+
+// EnsureCRDConversion points the conversion webhook of the Bookstore CRD at
+// clientConfig, so the API server trusts the current serving certificate.
+// The CRD is only patched if its conversion webhook differs.
+func EnsureCRDConversion(ctx context.Context, client dynamic.Interface, clientConfig ClientConfig) error {
+	webhookClientConfig := clientConfig.forPath(ConvertBookstorePath)
+	// A merge patch only touches the keys it names, so both addresses are
+	// listed and the one not in use is sent as null to clear it.
+	config := map[string]interface{}{
+		"url":      webhookClientConfig.URL,
+		"service":  webhookClientConfig.Service,
+		"caBundle": webhookClientConfig.CABundle,
+	}
+	upToDate, err := crdConversionUpToDate(ctx, client, config)
+	if err != nil || upToDate {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"conversion": map[string]interface{}{
+				"strategy": "Webhook",
+				"webhook": map[string]interface{}{
+					"conversionReviewVersions": []string{"v1"},
+					"clientConfig":             config,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = client.Resource(crdResource).Patch(ctx, BookstoreCRDName, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// crdConversionUpToDate reports whether the Bookstore CRD already converts
+// through a webhook with the client config config, as sent by
+// EnsureCRDConversion.
+func crdConversionUpToDate(ctx context.Context, client dynamic.Interface, config map[string]interface{}) (bool, error) {
+	crd, err := client.Resource(crdResource).Get(ctx, BookstoreCRDName, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	strategy, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "strategy")
+	existing, _, _ := unstructured.NestedFieldNoCopy(crd.Object, "spec", "conversion", "webhook", "clientConfig")
+	if strategy != "Webhook" || existing == nil {
+		return false, nil
+	}
+	// Both sides go through JSON so that byte slices and numbers compare
+	// the same way. The null fields config sends to clear an address are
+	// absent from the CRD.
+	var existingValue interface{}
+	var desiredValue map[string]interface{}
+	if err := roundTripJSON(existing, &existingValue); err != nil {
+		return false, err
+	}
+	if err := roundTripJSON(config, &desiredValue); err != nil {
+		return false, err
+	}
+	for key, value := range desiredValue {
+		if value == nil {
+			delete(desiredValue, key)
+		}
+	}
+	return equality.Semantic.DeepEqual(existingValue, interface{}(desiredValue)), nil
+}
+
+// roundTripJSON encodes in as JSON and decodes it into out.
+func roundTripJSON(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook serves the admission webhooks for Bookstore resources and
+// manages the certificate and configuration the API server needs to call them.
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Server serves webhooks over TLS using the certificate in its cert dir.
+type Server struct {
+	port    int
+	certDir string
+	mux     *http.ServeMux
+}
+
+// NewServer returns a Server listening on port and serving the certificate
+// written to certDir by EnsureServingCert.
+func NewServer(port int, certDir string) *Server {
+	return &Server{
+		port:    port,
+		certDir: certDir,
+		mux:     http.NewServeMux(),
+	}
+}
+
+// Register serves hook at path.
+func (s *Server) Register(path string, hook http.Handler) {
+	s.mux.Handle(path, hook)
+}
+
+// Run serves webhooks until ctx is cancelled, then shuts the server down
+// gracefully.
+func (s *Server) Run(ctx context.Context) error {
+	logger := klog.FromContext(ctx)
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", s.port),
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error(err, "Error shutting down webhook server")
+		}
+	}()
+
+	logger.Info("Starting webhook server", "port", s.port)
+	err := server.ListenAndServeTLS(filepath.Join(s.certDir, CertName), filepath.Join(s.certDir, KeyName))
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
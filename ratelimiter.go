@@ -0,0 +1,90 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+
+	configv1alpha1 "k8s.io/sample-controller/pkg/apis/config/v1alpha1"
+)
+
+// rateLimiter is the rate limiter of the workqueue. Like the max of a
+// workqueue.TypedItemExponentialFailureRateLimiter and a
+// workqueue.TypedBucketRateLimiter, it delays a Bookstore by the larger of
+// its exponential backoff and the delay of an overall token bucket. Unlike
+// them, it can be reconfigured while in use without losing the failure count
+// of the Bookstores that are backing off.
+type rateLimiter struct {
+	mu        sync.Mutex
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	failures  map[string]int
+	bucket    *rate.Limiter
+}
+
+var _ workqueue.TypedRateLimiter[string] = &rateLimiter{}
+
+func newRateLimiter(config configv1alpha1.RateLimiterConfiguration) *rateLimiter {
+	r := &rateLimiter{
+		failures: map[string]int{},
+		bucket:   rate.NewLimiter(rate.Limit(config.QPS), int(config.Burst)),
+	}
+	r.update(config)
+	return r
+}
+
+// update applies config. The new backoff applies from the next failure of a
+// Bookstore on.
+func (r *rateLimiter) update(config configv1alpha1.RateLimiterConfiguration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.baseDelay = config.BaseDelay.Duration
+	r.maxDelay = config.MaxDelay.Duration
+	r.bucket.SetLimit(rate.Limit(config.QPS))
+	r.bucket.SetBurst(int(config.Burst))
+}
+
+func (r *rateLimiter) When(item string) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	exp := r.failures[item]
+	r.failures[item]++
+	backoff := float64(r.baseDelay.Nanoseconds()) * math.Pow(2, float64(exp))
+	delay := r.maxDelay
+	if backoff < float64(r.maxDelay.Nanoseconds()) {
+		delay = time.Duration(backoff)
+	}
+	return max(delay, r.bucket.Reserve().Delay())
+}
+
+func (r *rateLimiter) NumRequeues(item string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failures[item]
+}
+
+func (r *rateLimiter) Forget(item string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.failures, item)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	configv1alpha1 "k8s.io/sample-controller/pkg/apis/config/v1alpha1"
+)
+
+// startWorkers starts the pool of workers that run until ctx is cancelled.
+// It starts workers of them, unless SetWorkers was called before with
+// another number.
+func (c *Controller) startWorkers(ctx context.Context, workers int) {
+	c.workersMu.Lock()
+	defer c.workersMu.Unlock()
+	c.workersCtx = ctx
+	if c.workers == 0 {
+		c.workers = workers
+	}
+	c.scaleWorkersLocked()
+}
+
+// SetWorkers changes the number of workers to n. New workers start at once,
+// while removed workers stop gracefully: after the item they are processing,
+// or after the next one if they are idle. Before Run, it sets the number of
+// workers Run starts.
+func (c *Controller) SetWorkers(n int) {
+	c.workersMu.Lock()
+	defer c.workersMu.Unlock()
+	c.workers = n
+	if c.workersCtx != nil {
+		c.scaleWorkersLocked()
+	}
+}
+
+// scaleWorkersLocked starts or stops workers until there are c.workers.
+func (c *Controller) scaleWorkersLocked() {
+	logger := klog.FromContext(c.workersCtx)
+	if len(c.workerCancels) != c.workers {
+		logger.Info("Scaling workers", "from", len(c.workerCancels), "to", c.workers)
+	}
+	for len(c.workerCancels) < c.workers {
+		ctx, cancel := context.WithCancel(c.workersCtx)
+		c.workerCancels = append(c.workerCancels, cancel)
+		c.workersWG.Add(1)
+		go func() {
+			defer c.workersWG.Done()
+			wait.UntilWithContext(ctx, c.runWorker, time.Second)
+		}()
+	}
+	for len(c.workerCancels) > c.workers {
+		last := len(c.workerCancels) - 1
+		c.workerCancels[last]()
+		c.workerCancels = c.workerCancels[:last]
+	}
+}
+
+// SetRateLimiter reconfigures the rate limiter of the workqueue.
+func (c *Controller) SetRateLimiter(config configv1alpha1.RateLimiterConfiguration) {
+	c.rateLimiter.update(config)
+}